@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zostay/genifest/pkg/manager/k8s"
+)
+
+// These are the output formats that may be selected for reporting errors.
+const (
+	OutputText   = "text"   // errors are reported only in the log output
+	OutputGitHub = "github" // errors are also reported as GitHub Actions annotations
+)
+
+// checkOutputFormat returns an error if the output format is not known.
+func checkOutputFormat(format string) error {
+	switch format {
+	case OutputText, OutputGitHub:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// annotateErrors writes the errors collected in err as annotations suitable for
+// the given output format. Nothing is written for the text format.
+func annotateErrors(format string, err error) {
+	if format != OutputGitHub {
+		return
+	}
+
+	// errors joined from several clusters are annotated one at a time
+	if _, isGenErr := err.(*k8s.GenerateError); !isGenErr {
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				annotateErrors(format, e)
+			}
+			return
+		}
+	}
+
+	var genErr *k8s.GenerateError
+
+	if !errors.As(err, &genErr) {
+		fmt.Printf("::error::%s\n", escapeGitHubData(err.Error()))
+		return
+	}
+
+	for _, fe := range genErr.Errs {
+		fmt.Printf("::error file=%s::%s\n",
+			escapeGitHubProperty(relativePath(fe.File)),
+			escapeGitHubData(fe.Err.Error()))
+	}
+}
+
+// relativePath returns the path relative to the working directory, if
+// possible, as GitHub expects paths relative to the workspace.
+func relativePath(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(wd, path)
+	if err != nil {
+		return path
+	}
+
+	return rel
+}
+
+// escapeGitHubData escapes the message of a GitHub workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a GitHub workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zostay/genifest/pkg/log"
+)
+
+var (
+	// dumpConfigCmd is the command configuration for dump-config.
+	dumpConfigCmd = &cobra.Command{
+		Use:   "dump-config",
+		Short: "Print the fully merged configuration as YAML",
+		Args:  cobra.NoArgs,
+		Run:   RunDumpConfig,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(dumpConfigCmd)
+}
+
+// RunDumpConfig prints the configuration as genifest sees it after merging the
+// configuration files and environment and after selecting clusters. Only the
+// settings genifest decodes are printed, so values merged in from the secret
+// configuration file for other tools are never shown.
+func RunDumpConfig(_ *cobra.Command, _ []string) {
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(settingsOf(reflect.ValueOf(c))); err != nil {
+		log.LineAndSayf("FATAL", "Unable to dump configuration: %v", err)
+		os.Exit(ExitGeneral)
+	}
+}
+
+// settingsOf converts the decoded configuration back into settings, naming the
+// fields of each struct the way they are named in the configuration file.
+func settingsOf(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return settingsOf(v.Elem())
+	case reflect.Struct:
+		settings := map[string]any{}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+			switch name {
+			case "-":
+				continue
+			case "":
+				name = strings.ToLower(f.Name)
+			}
+
+			settings[name] = settingsOf(v.Field(i))
+		}
+		return settings
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		settings := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			settings[iter.Key().String()] = settingsOf(iter.Value())
+		}
+		return settings
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		list := make([]any, v.Len())
+		for i := range list {
+			list[i] = settingsOf(v.Index(i))
+		}
+		return list
+	default:
+		return v.Interface()
+	}
+}
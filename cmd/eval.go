@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zostay/genifest/pkg/config"
+	"github.com/zostay/genifest/pkg/log"
+)
+
+var (
+	// evalCmd is the command configuration for eval.
+	evalCmd = &cobra.Command{
+		Use:   "eval [template]",
+		Short: "Render an inline template with the template functions of a cluster",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  RunEval,
+	}
+
+	evalTemplateFile string
+	evalSourceFile   string
+	evalDisableApi   bool
+	evalSkipSecrets  bool
+)
+
+func init() {
+	evalCmd.Flags().StringVar(&evalTemplateFile, "template-file", "", "read the template from this file instead of the argument")
+	evalCmd.Flags().StringVar(&evalSourceFile, "file", "eval/eval.yaml", "source file, relative to the source_dir, reported to the template by sourcePath")
+	evalCmd.Flags().BoolVar(&evalDisableApi, "disable-api", false, "prevent kubernetes API calls")
+	evalCmd.Flags().BoolVar(&evalSkipSecrets, "skip-secrets", true, "fail templates that call the secret functions instead of running them")
+
+	rootCmd.AddCommand(evalCmd)
+}
+
+// RunEval renders a template given on the command line, or read from a file,
+// using the same template functions used for source templates and prints the
+// result. The template uses the same {{{ }}} delimiters as source templates. It
+// works with a single cluster, so when more than one is configured, one must be
+// chosen with --cluster-name.
+func RunEval(cmd *cobra.Command, args []string) error {
+	var tmpl string
+	switch {
+	case evalTemplateFile != "" && len(args) > 0:
+		return fmt.Errorf("give either a template argument or --template-file, not both")
+	case evalTemplateFile != "":
+		bs, err := os.ReadFile(evalTemplateFile)
+		if err != nil {
+			return err
+		}
+		tmpl = string(bs)
+	case len(args) > 0:
+		tmpl = args[0]
+	default:
+		return fmt.Errorf("a template argument or --template-file is required")
+	}
+
+	cmd.SilenceUsage = true
+
+	names, err := inspectClusters()
+	if err != nil {
+		return err
+	}
+
+	if len(names) > 1 {
+		err := fmt.Errorf("%d clusters are configured, choose one with --cluster-name", len(names))
+		log.LineAndSayf("FATAL", "%v", err)
+		return &ExitError{ExitConfig, err}
+	}
+
+	cluster := c.Clusters[names[0]]
+	out, err := renderTemplate(&cluster, evalSourceFile, tmpl, evalDisableApi, evalSkipSecrets)
+	if err != nil {
+		log.LineAndSayf("FATAL", "%v", err)
+		return &ExitError{ExitGenerate, err}
+	}
+
+	fmt.Print(out)
+
+	return nil
+}
+
+// renderTemplate renders the template using the template functions of the
+// cluster. The source file, relative to the source_dir, is what sourcePath
+// reports to the template. When skipSecrets is set, the secret functions fail
+// rather than run, so secrets cannot end up in the output.
+func renderTemplate(
+	cluster *config.Cluster,
+	sourceFile, tmpl string,
+	disableApi, skipSecrets bool,
+) (string, error) {
+	tools := c.Tools(cluster, disableApi)
+	rmgr, err := tools.ResMgr(context.Background(), skipSecrets)
+	if err != nil {
+		return "", fmt.Errorf("tools.ResMgr(): %w", err)
+	}
+
+	name := sourceFile
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(cluster.SourceDir, name)
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(c.CloudHome, name)
+	}
+
+	return rmgr.TemplateConfigFile(name, []byte(tmpl))
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "fmt"
+
+// These are the exit codes genifest uses so that automation can tell different
+// kinds of failure apart.
+const (
+	ExitGeneral  = 1 // any failure not covered by another code
+	ExitConfig   = 2 // configuration could not be loaded or is invalid
+	ExitDrift    = 3 // generated resources differ from what was expected
+	ExitGenerate = 4 // generating resources from source templates failed
+)
+
+// ExitError is returned by a command to select the exit code used by genifest.
+// The command is expected to have already reported the error to the user.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the message of the wrapped error.
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit %d: %v", e.Code, e.Err)
+}
+
+// Unwrap returns the wrapped error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zostay/genifest/pkg/config"
+	"github.com/zostay/genifest/pkg/log"
+)
+
+var (
+	// listFunctionsCmd is the command configuration for list-functions.
+	listFunctionsCmd = &cobra.Command{
+		Use:   "list-functions",
+		Short: "List the template functions available to source templates",
+		Args:  cobra.NoArgs,
+		Run:   RunListFunctions,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(listFunctionsCmd)
+}
+
+// RunListFunctions prints the name and signature of every genifest template
+// function. The sprig functions are also available, but are not listed.
+func RunListFunctions(_ *cobra.Command, _ []string) {
+	tools := c.Tools(&config.Cluster{}, true)
+	rmgr, err := tools.ResMgr(context.Background(), false)
+	if err != nil {
+		log.LineAndSayf("FATAL", "Unable to setup template functions: %v", err)
+		os.Exit(ExitGeneral)
+	}
+
+	funcMap := rmgr.FuncMap()
+	names := make([]string, 0, len(funcMap))
+	for name := range funcMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("%s %s\n", name, reflect.TypeOf(funcMap[name]))
+	}
+}
@@ -2,14 +2,15 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"github.com/zostay/genifest/pkg/manager/k8s"
-
+	"github.com/zostay/genifest/pkg/envtools"
 	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/manager/k8s"
 )
 
 var (
@@ -18,50 +19,199 @@ var (
 		Use:   "run",
 		Short: "Generate deployment manifests from template source for gitops",
 		Args:  cobra.MaximumNArgs(1),
-		Run:   RunGenerateManifests,
+		RunE:  RunGenerateManifests,
 	}
 
-	skipSecrets bool
-	disableApi  bool
+	skipSecrets    bool
+	disableApi     bool
+	changedOnly    bool
+	summaryOnly    bool
+	quietNoOp      bool
+	failOnEmpty    bool
+	keepGoing      bool
+	failOnConflict bool
+	apply          bool
+	allowCluster   bool
+	dryRun         string
+	maxFileSize    int64
+	noCache        bool
+	scope          string
+	output         string
+	postWrite      string
+	cacheTTL       time.Duration
+	timeLimit      time.Duration
+	maxDepth       int
+
+	envFile         string
+	envFileOverride bool
 )
 
 func init() {
 	generateManifestsCmd.Flags().BoolVar(&skipSecrets, "skip-secrets", true, "skip generating deploy manifests containing secrets")
 	generateManifestsCmd.Flags().BoolVar(&disableApi, "disable-api", false, "prevent kubernetes API calls")
+	generateManifestsCmd.Flags().BoolVar(&changedOnly, "changed-only", false, "only report on source files whose generated resources changed")
+	generateManifestsCmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "only report the final summary of the run")
+	generateManifestsCmd.Flags().BoolVar(&quietNoOp, "summary-only-on-change", false, "like --summary-only, but print nothing at all when no resources were modified")
+	generateManifestsCmd.Flags().Int64Var(&maxFileSize, "max-file-size", 4<<20, "skip source files larger than this many bytes (0 for no limit)")
+	generateManifestsCmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty", false, "fail on source files that contain no resources instead of skipping them")
+	generateManifestsCmd.Flags().BoolVar(&failOnConflict, "fail-on-conflict", false, "fail when two resources are saved to the same resource file")
+	generateManifestsCmd.Flags().BoolVar(&keepGoing, "keep-going", false, "continue with the remaining documents and clusters after one fails")
+	generateManifestsCmd.Flags().StringVar(&scope, "scope", "", "only generate from source files within this directory of the source_dir")
+	generateManifestsCmd.Flags().StringVar(&output, "output", OutputText, "error output format: text or github")
+	generateManifestsCmd.Flags().BoolVar(&apply, "apply", false, "server-side apply every generated resource to the cluster (requires --allow-cluster-access)")
+	generateManifestsCmd.Flags().BoolVar(&allowCluster, "allow-cluster-access", false, "permit --apply to change the cluster")
+	generateManifestsCmd.Flags().StringVar(&dryRun, "dry-run", "none", "with --apply, none to apply or server for a server-side dry run")
+	generateManifestsCmd.Flags().StringVar(&postWrite, "post-write", "", "shell command to run from cloud home after all resource files are written")
+	generateManifestsCmd.Flags().BoolVar(&noCache, "no-cache", false, "always fetch network values instead of using the cache")
+	generateManifestsCmd.Flags().DurationVar(&cacheTTL, "cache-ttl", 0, "how long cached network values are used (default cache_ttl or 1h)")
+	generateManifestsCmd.Flags().DurationVar(&timeLimit, "time-limit", 0, "abort the run if it takes longer than this (0 for no limit)")
+	generateManifestsCmd.Flags().IntVar(&maxDepth, "max-evaluation-depth", 0, "limit how deeply applyTemplate may be nested (default max_evaluation_depth or 100)")
+	generateManifestsCmd.Flags().StringVar(&envFile, "env-file", "", "load environment variables from this dotenv file for the run")
+	generateManifestsCmd.Flags().BoolVar(&envFileOverride, "env-file-override", false, "let variables in --env-file replace those already set")
 }
 
 // RunGenerateManifests performs argument parsing and startup, generates
 // deployment manifests from source templates, and reports any errors that
 // occur.
-func RunGenerateManifests(_ *cobra.Command, args []string) {
+func RunGenerateManifests(cmd *cobra.Command, args []string) error {
+	if err := checkOutputFormat(output); err != nil {
+		return err
+	}
+
+	if apply {
+		switch {
+		case !allowCluster:
+			return fmt.Errorf("--apply changes the cluster and requires --allow-cluster-access")
+		case disableApi:
+			return fmt.Errorf("--apply cannot be used with --disable-api")
+		}
+	}
+
+	switch {
+	case dryRun != "none" && dryRun != "server":
+		return fmt.Errorf("unknown --dry-run mode %q, expected none or server", dryRun)
+	case dryRun == "server" && !apply:
+		return fmt.Errorf("--dry-run=server does nothing without --apply")
+	}
+
+	cmd.SilenceUsage = true
+
+	if err := validateConfig(); err != nil {
+		return err
+	}
+
+	if noCache {
+		c.NoCache = true
+	}
+	if cacheTTL > 0 {
+		c.CacheTTL = cacheTTL
+	}
+	if maxDepth > 0 {
+		c.MaxDepth = maxDepth
+	}
+
 	match := ""
 	if len(args) > 0 {
 		match = args[0]
 	}
 
+	if envFile != "" {
+		err := envtools.LoadEnvFile(envFile, envFileOverride)
+		if err != nil {
+			log.LineAndSayf("FATAL", "Unable to load environment file: %v", err)
+			return &ExitError{ExitConfig, err}
+		}
+	}
+
 	ctx := context.Background()
+	if timeLimit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeLimit)
+		defer cancel()
+	}
 
 	sayMatch := match
 	if sayMatch == "" {
 		sayMatch = "all"
 	}
 	sayMatch = "matching " + sayMatch
-	log.LineAndSayf(
+
+	// with --summary-only-on-change, only log until we know something changed
+	say := log.LineAndSayf
+	if quietNoOp {
+		say = log.Linef
+	}
+
+	say(
 		"TASK",
 		"Generate manifests from source configurations %s",
 		sayMatch)
 
-	var err error
-	for _, cluster := range c.Clusters {
-		err = k8s.GenerateK8sResources(ctx, c, &cluster, match, skipSecrets, disableApi)
+	opts := k8s.Options{
+		SkipSecrets:    skipSecrets,
+		DisableApi:     disableApi,
+		ChangedOnly:    changedOnly,
+		SummaryOnly:    summaryOnly || quietNoOp,
+		MaxFileSize:    maxFileSize,
+		Scope:          scope,
+		FailOnEmpty:    failOnEmpty,
+		FailOnConflict: failOnConflict,
+		KeepGoing:      keepGoing,
+		Apply:          apply,
+		DryRunServer:   dryRun == "server",
+	}
+
+	var (
+		errs  []error
+		stats k8s.Stats
+	)
+	for name, cluster := range c.Clusters {
+		clusterStats, err := k8s.GenerateK8sResources(ctx, c, &cluster, match, opts)
+		stats.Add(clusterStats)
 		if err != nil {
-			err = fmt.Errorf("GenerateManifests: %w", err)
-			break
+			if !keepGoing {
+				errs = append(errs, fmt.Errorf("GenerateManifests: %w", err))
+				break
+			}
+
+			log.LineAndSayf("ERROR", "Cluster %q failed, continuing with the rest because of --keep-going", name)
+			errs = append(errs, fmt.Errorf("GenerateManifests(%s): %w", name, err))
 		}
 	}
 
+	err := errors.Join(errs...)
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			log.LineAndSayf("FATAL", "Run exceeded the time limit of %v", timeLimit)
+		}
 		log.LineAndSayf("FATAL", "%v", err)
-		os.Exit(1)
+		annotateErrors(output, err)
+		return &ExitError{ExitGenerate, err}
 	}
+
+	if stats.Modified > 0 {
+		say = log.LineAndSayf
+	}
+
+	if postWrite != "" {
+		say("TASK", "Run post-write hook on %d modified files", len(stats.ModifiedFiles))
+		if err := runPostWrite(ctx, postWrite, c.CloudHome, stats.ModifiedFiles); err != nil {
+			log.LineAndSayf("FATAL", "%v", err)
+			return &ExitError{ExitGeneral, err}
+		}
+	}
+
+	if apply {
+		say(
+			"APPLY",
+			"Created %d, updated %d, and left %d resources unchanged in the cluster (dry run %s).",
+			stats.Created, stats.Updated, stats.Unchanged, dryRun)
+	}
+
+	say(
+		"DONE",
+		"Processed %d source files, saved %d resources, modified %d resources in %d files, skipped %d resources.",
+		stats.Files, stats.Saved, stats.Modified, stats.FilesModified, stats.Skipped)
+
+	return nil
 }
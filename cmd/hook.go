@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ModifiedFilesEnv names the environment variable used to pass the modified
+// resource files to the post-write hook, one path per line.
+const ModifiedFilesEnv = "GENIFEST_MODIFIED_FILES"
+
+// runPostWrite runs the post-write hook command through the shell from the
+// cloud home directory. The modified files are passed both as the positional
+// arguments of the command and in the ModifiedFilesEnv environment variable.
+func runPostWrite(ctx context.Context, cmdline, cloudHome string, files []string) error {
+	args := append([]string{"-c", cmdline, "genifest"}, files...)
+	hook := exec.CommandContext(ctx, "sh", args...) //nolint:gosec // the hook is given by the user
+	hook.Dir = cloudHome
+	hook.Env = append(os.Environ(), ModifiedFilesEnv+"="+strings.Join(files, "\n"))
+	hook.Stdin = os.Stdin
+	hook.Stdout = os.Stdout
+	hook.Stderr = os.Stderr
+
+	if err := hook.Run(); err != nil {
+		return fmt.Errorf("post-write hook %q: %w", cmdline, err)
+	}
+
+	return nil
+}
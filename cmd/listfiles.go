@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zostay/genifest/pkg/log"
+)
+
+var (
+	// listFilesCmd is the command configuration for list-files.
+	listFilesCmd = &cobra.Command{
+		Use:   "list-files",
+		Short: "List the files available to the file template function",
+		Args:  cobra.NoArgs,
+		Run:   RunListFiles,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(listFilesCmd)
+}
+
+// RunListFiles prints the files directory searched by the file template
+// function for each cluster along with every file found within it.
+func RunListFiles(_ *cobra.Command, _ []string) {
+	names := clusterNames()
+
+	failed := false
+	for _, name := range names {
+		cluster := c.Clusters[name]
+		filesRoot := cluster.FilesRoot(c.CloudHome)
+		fmt.Printf("%s: %s\n", name, filesRoot)
+
+		err := filepath.WalkDir(filesRoot, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(filesRoot, path)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("    %s\n", rel)
+			return nil
+		})
+		if err != nil {
+			log.LineAndSayf("ERROR", "Unable to list files for cluster %q: %v", name, err)
+			failed = true
+		}
+	}
+
+	if failed {
+		os.Exit(ExitGeneral)
+	}
+}
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -20,12 +22,16 @@ var (
 	logStderr   bool
 	configFile  string
 	clusterName string
+	warnAsError bool
+	rootMarker  string
+	strict      bool
 
 	c *config.Config
 
 	rootCmd = &cobra.Command{
-		Use:   "genifest",
-		Short: "Prepare the configuration of the kubenetes cluster from templates",
+		Use:           "genifest",
+		Short:         "Prepare the configuration of the kubenetes cluster from templates",
+		SilenceErrors: true,
 	}
 
 	printVersionCmd = &cobra.Command{
@@ -43,6 +49,9 @@ func init() {
 	rootCmd.PersistentFlags().BoolVar(&logStderr, "log-to-stderr", false, "send logs to stdout only, skip logging to file")
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "name of the configuration file to use")
 	rootCmd.PersistentFlags().StringVarP(&clusterName, "cluster-name", "c", "", "only work with the cluster with this name")
+	rootCmd.PersistentFlags().StringVar(&rootMarker, "root-marker", config.DefaultRootMarker, "file marking the root directory, found by searching up from the working directory")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "fail if the configuration contains unknown settings")
+	rootCmd.PersistentFlags().BoolVar(&warnAsError, "warn-as-error", false, "fail if any warnings occur while loading configuration")
 
 	rootCmd.AddCommand(generateManifestsCmd, printVersionCmd)
 }
@@ -50,16 +59,21 @@ func init() {
 func initConfig() {
 	var err error
 
-	c, err = config.InitConfig(configFile)
+	c, err = config.InitConfig(configFile, rootMarker, strict)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "FATAL Unable to load configuration %q: %v\n", configFile, err)
-		os.Exit(1)
+		os.Exit(ExitConfig)
+	}
+
+	if warnAsError && len(c.Warnings) > 0 {
+		fmt.Fprintf(os.Stderr, "FATAL %d warnings while loading configuration and --warn-as-error is set\n", len(c.Warnings))
+		os.Exit(ExitConfig)
 	}
 
 	err = log.Setup(c.CloudHome, "", logStderr, false)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Unable to rotate and open log file: %v\n", err)
-		os.Exit(1)
+		os.Exit(ExitGeneral)
 	}
 
 	log.Line("START", strings.Repeat("#", 78))
@@ -90,7 +104,7 @@ func initConfig() {
 
 	if !found {
 		log.LineAndSayf("FATAL", "No cluster configuration named %q\n", clusterName)
-		os.Exit(1)
+		os.Exit(ExitConfig)
 	}
 
 	if c.CloudHome == "" {
@@ -98,29 +112,73 @@ func initConfig() {
 		c.CloudHome, err = os.Getwd()
 		if err != nil {
 			log.LineAndSayf("FATAL", "Please set GENIFEST_HOME in your environment.\n")
-			os.Exit(1)
+			os.Exit(ExitConfig)
 		}
 	}
 
 	if c.CloudHome == "" {
 		log.LineAndSayf("FATAL", "Please set GENIFEST_HOME in your environment.\n")
-		os.Exit(1)
+		os.Exit(ExitConfig)
 	}
 
 	validCloudHome, err := regexp.MatchString(`^[a-zA-Z0-9_./-]+$`, c.CloudHome)
 	if err != nil {
 		log.LineAndSayf("FATAL", "GENIFEST_HOME contains illegal characters.\n")
-		os.Exit(1)
+		os.Exit(ExitConfig)
 	}
 
 	if !validCloudHome {
 		fmt.Fprintf(os.Stderr, "Please set GENIFEST_HOME to a valid value.\n")
-		os.Exit(1)
+		os.Exit(ExitConfig)
 	}
 }
 
+// validateConfig checks the configuration before a command that generates from
+// it. Other commands, such as dump-config, leave it unchecked so they can be
+// used to debug an invalid configuration.
+func validateConfig() error {
+	if err := c.Validate(); err != nil {
+		log.LineAndSayf("FATAL", "Invalid configuration:\n%v", err)
+		return &ExitError{ExitConfig, err}
+	}
+
+	return nil
+}
+
+// inspectClusters prepares a command that only inspects what would be
+// generated, such as verify or test. It validates the configuration and makes
+// the state file read-only, so new state is never stored. It returns the names
+// of the clusters to work on, sorted.
+func inspectClusters() ([]string, error) {
+	if err := validateConfig(); err != nil {
+		return nil, err
+	}
+
+	c.ReadOnlyState = true
+
+	return clusterNames(), nil
+}
+
+// clusterNames returns the names of the clusters to work on, sorted.
+func clusterNames() []string {
+	names := make([]string, 0, len(c.Clusters))
+	for name := range c.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Execute runs the genifest command line. When a command fails with an
+// ExitError, genifest exits with the code it carries.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+
 		cobra.CheckErr(err)
 	}
 }
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zostay/genifest/pkg/log"
+)
+
+var (
+	// testCmd is the command configuration for test.
+	testCmd = &cobra.Command{
+		Use:   "test [tests-file]",
+		Short: "Check template snippets against their expected output",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  RunTest,
+	}
+
+	testDisableApi  bool
+	testSkipSecrets bool
+)
+
+// DefaultTestsFile is the tests file read by the test command, relative to the
+// cloud home, when none is given.
+const DefaultTestsFile = "genifest-tests.yaml"
+
+// templateTest is a single test case read from the tests file.
+type templateTest struct {
+	// Name identifies the test in the report.
+	Name string `yaml:"name"`
+
+	// Cluster limits the test to the named cluster. The test runs against
+	// every cluster when empty.
+	Cluster string `yaml:"cluster"`
+
+	// File is the source file, relative to the source_dir, reported to the
+	// template by sourcePath.
+	File string `yaml:"file"`
+
+	// Template is rendered with the template functions of the cluster.
+	Template string `yaml:"template"`
+
+	// Expect is the output the template must render exactly.
+	Expect string `yaml:"expect"`
+}
+
+func init() {
+	testCmd.Flags().BoolVar(&testDisableApi, "disable-api", true, "prevent kubernetes API calls")
+	testCmd.Flags().BoolVar(&testSkipSecrets, "skip-secrets", true, "fail templates that call the secret functions instead of running them")
+
+	rootCmd.AddCommand(testCmd)
+}
+
+// RunTest reads the tests file, which holds a list of tests under the tests
+// key, renders the template of each test for each cluster, and reports whether
+// the output matches what was expected. It fails if any test fails.
+func RunTest(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	names, err := inspectClusters()
+	if err != nil {
+		return err
+	}
+
+	testsFile := DefaultTestsFile
+	if len(args) > 0 {
+		testsFile = args[0]
+	}
+	if !filepath.IsAbs(testsFile) {
+		testsFile = filepath.Join(c.CloudHome, testsFile)
+	}
+
+	bs, err := os.ReadFile(testsFile)
+	if err != nil {
+		log.LineAndSayf("FATAL", "Unable to read tests: %v", err)
+		return &ExitError{ExitConfig, err}
+	}
+
+	var tests struct {
+		Tests []templateTest `yaml:"tests"`
+	}
+	if err := yaml.Unmarshal(bs, &tests); err != nil {
+		log.LineAndSayf("FATAL", "Unable to parse %q: %v", testsFile, err)
+		return &ExitError{ExitConfig, err}
+	}
+
+	passed, failed := 0, 0
+	for _, tc := range tests.Tests {
+		file := tc.File
+		if file == "" {
+			file = "test/test.yaml"
+		}
+
+		for _, name := range names {
+			if tc.Cluster != "" && tc.Cluster != name {
+				continue
+			}
+
+			cluster := c.Clusters[name]
+			out, err := renderTemplate(&cluster, file, tc.Template, testDisableApi, testSkipSecrets)
+			switch {
+			case err != nil:
+				fmt.Printf("FAIL %s (%s): %v\n", tc.Name, name, err)
+				failed++
+			case out != tc.Expect:
+				fmt.Printf("FAIL %s (%s): expected %q, got %q\n", tc.Name, name, tc.Expect, out)
+				failed++
+			default:
+				fmt.Printf("PASS %s (%s)\n", tc.Name, name)
+				passed++
+			}
+		}
+	}
+
+	log.LineAndSayf("DONE", "%d passed, %d failed.", passed, failed)
+
+	if failed > 0 {
+		return &ExitError{ExitGeneral, fmt.Errorf("%d tests failed", failed)}
+	}
+
+	return nil
+}
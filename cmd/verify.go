@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zostay/genifest/pkg/config"
+	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/manager/k8s"
+)
+
+var (
+	// verifyCmd is the command configuration for verify.
+	verifyCmd = &cobra.Command{
+		Use:   "verify",
+		Short: "Check that generated manifests match the expected snapshots",
+		Args:  cobra.NoArgs,
+		RunE:  RunVerify,
+	}
+
+	expectedDir    string
+	updateExpected bool
+)
+
+func init() {
+	verifyCmd.Flags().StringVar(&expectedDir, "expected-dir", "expected", "directory holding the expected snapshots, one subdirectory per cluster")
+	verifyCmd.Flags().BoolVar(&updateExpected, "update", false, "replace the expected snapshots with the generated manifests")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+// RunVerify generates the deployment manifests of each cluster into a scratch
+// directory and compares them against the expected snapshots, reporting every
+// difference found. With --update, the snapshots are regenerated instead.
+//
+// Unlike run, verify takes no match argument. The snapshots of a cluster cover
+// every source file, so generating only some of them would report the rest as
+// drift or, with --update, discard their snapshots.
+func RunVerify(cmd *cobra.Command, _ []string) error {
+	cmd.SilenceUsage = true
+
+	names, err := inspectClusters()
+	if err != nil {
+		return err
+	}
+
+	root := expectedDir
+	if !filepath.IsAbs(root) {
+		root = filepath.Join(c.CloudHome, root)
+	}
+
+	ctx := context.Background()
+	opts := k8s.Options{
+		SkipSecrets: true,
+		DisableApi:  true,
+		SummaryOnly: true,
+	}
+
+	drift := 0
+	for _, name := range names {
+		cluster := c.Clusters[name]
+		snapDir := filepath.Join(root, name)
+
+		if updateExpected {
+			log.LineAndSayf("UPDATE", "Regenerating expected snapshots for cluster %q in %q", name, snapDir)
+			if code, err := updateCluster(ctx, &cluster, snapDir, opts); err != nil {
+				log.LineAndSayf("FATAL", "%v", err)
+				return &ExitError{code, err}
+			}
+
+			continue
+		}
+
+		diffs, code, err := verifyCluster(ctx, &cluster, snapDir, opts)
+		if err != nil {
+			log.LineAndSayf("FATAL", "%v", err)
+			return &ExitError{code, err}
+		}
+
+		for _, d := range diffs {
+			drift++
+			path := filepath.Join(snapDir, d.Path)
+			switch {
+			case d.Expected == nil:
+				fmt.Printf("--- /dev/null\n+++ %s (not expected)\n", path)
+			case d.Actual == nil:
+				fmt.Printf("--- %s (not generated)\n+++ /dev/null\n", path)
+			default:
+				fmt.Printf("--- %s (expected)\n+++ %s (generated)\n", path, path)
+			}
+			fmt.Print(k8s.LineDiff(string(d.Expected), string(d.Actual)))
+		}
+	}
+
+	if drift > 0 {
+		err := fmt.Errorf("%d generated files differ from the expected snapshots", drift)
+		log.LineAndSayf("FAIL", "%v", err)
+		return &ExitError{ExitDrift, err}
+	}
+
+	if !updateExpected {
+		log.LineAndSayf("DONE", "Generated manifests match the expected snapshots.")
+	}
+
+	return nil
+}
+
+// updateCluster replaces the snapshots in snapDir with the manifests generated
+// for a single cluster. The manifests are generated next to snapDir first, so
+// the snapshots are left alone if generation fails. On error, the exit code to
+// use is returned as well.
+func updateCluster(
+	ctx context.Context,
+	cluster *config.Cluster,
+	snapDir string,
+	opts k8s.Options,
+) (int, error) {
+	parent := filepath.Dir(snapDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return ExitGeneral, fmt.Errorf("unable to create %q: %w", parent, err)
+	}
+
+	// generate on the same filesystem so the result can be renamed into place
+	scratch, err := os.MkdirTemp(parent, ".genifest-update-")
+	if err != nil {
+		return ExitGeneral, fmt.Errorf("unable to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	opts.DeployDir = scratch
+	if _, err := k8s.GenerateK8sResources(ctx, c, cluster, "", opts); err != nil {
+		return ExitGenerate, err
+	}
+
+	// os.MkdirTemp creates the directory readable only by its owner
+	if err := os.Chmod(scratch, 0755); err != nil {
+		return ExitGeneral, fmt.Errorf("unable to set permissions of %q: %w", scratch, err)
+	}
+
+	if err := os.RemoveAll(snapDir); err != nil {
+		return ExitGeneral, fmt.Errorf("unable to clear %q: %w", snapDir, err)
+	}
+
+	if err := os.Rename(scratch, snapDir); err != nil {
+		return ExitGeneral, fmt.Errorf("unable to move snapshots into %q: %w", snapDir, err)
+	}
+
+	return 0, nil
+}
+
+// verifyCluster generates the manifests for a single cluster into a scratch
+// directory and compares them against the snapshots in snapDir. On error, the
+// exit code to use is returned as well.
+func verifyCluster(
+	ctx context.Context,
+	cluster *config.Cluster,
+	snapDir string,
+	opts k8s.Options,
+) ([]k8s.Difference, int, error) {
+	scratch, err := os.MkdirTemp("", "genifest-verify-")
+	if err != nil {
+		return nil, ExitGeneral, fmt.Errorf("unable to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	opts.DeployDir = scratch
+	if _, err := k8s.GenerateK8sResources(ctx, c, cluster, "", opts); err != nil {
+		return nil, ExitGenerate, err
+	}
+
+	diffs, err := k8s.CompareDirs(snapDir, scratch)
+	if err != nil {
+		return nil, ExitGeneral, fmt.Errorf("unable to compare with snapshots: %w", err)
+	}
+
+	return diffs, 0, nil
+}
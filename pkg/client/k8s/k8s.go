@@ -105,8 +105,9 @@ func MakeSecretApplyConfigResource(
 
 // MakeSealedSecretResource constructs a bitnami sealed secret object for the
 // given namespace, name, and data to encrypt. It will perform the encryption of
-// that data.
+// that data, killing kubeseal if the context is done first.
 func MakeSealedSecretResource(
+	ctx context.Context,
 	ns,
 	name string,
 	data map[string]string,
@@ -114,7 +115,7 @@ func MakeSealedSecretResource(
 	encryptedData := make(map[string]string, len(data))
 	for k, v := range data {
 		var err error
-		encryptedData[k], err = cfgstr.KubeSeal(ns, name, v)
+		encryptedData[k], err = cfgstr.KubeSeal(ctx, ns, name, v)
 		if err != nil {
 			return nil, err
 		}
@@ -139,12 +140,13 @@ func MakeSealedSecretResource(
 // MakeAccessKeySecretResource is syntactic sugar around MakeSecretResource()
 // for use with access key information.
 func MakeAccessKeySecretResource(
+	ctx context.Context,
 	ns,
 	name,
 	accessKey,
 	secretKey string,
 ) (*bitnamiv1alpha1.SealedSecret, error) {
-	return MakeSealedSecretResource(ns, name, map[string]string{
+	return MakeSealedSecretResource(ctx, ns, name, map[string]string{
 		AwsAccessKeyId:  accessKey,
 		SecretAccessKey: secretKey,
 	})
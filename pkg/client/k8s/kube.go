@@ -22,6 +22,34 @@ type Client struct {
 	mapper *restmapper.DeferredDiscoveryRESTMapper // gvr map
 }
 
+// CurrentContext returns the name of the current context set in the local
+// kubeconfig or returns an error if no kubeconfig is found.
+func CurrentContext() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot find HOME: %w", err)
+	}
+
+	kubeconfig := filepath.Join(home, ".kube", "config")
+	if _, err := os.Stat(kubeconfig); err != nil {
+		return "", fmt.Errorf("no kubeconfig found at %q: %w", kubeconfig, err)
+	}
+
+	lr := clientcmd.NewDefaultClientConfigLoadingRules()
+	lr.ExplicitPath = kubeconfig
+
+	cfg, err := lr.Load()
+	if err != nil {
+		return "", fmt.Errorf("error loading kubeconfig %q: %w", kubeconfig, err)
+	}
+
+	if cfg.CurrentContext == "" {
+		return "", fmt.Errorf("kubeconfig %q has no current context", kubeconfig)
+	}
+
+	return cfg.CurrentContext, nil
+}
+
 // New returns a new kubernetes client from local configuration or returns an
 // error.
 func New(context string) (*Client, error) {
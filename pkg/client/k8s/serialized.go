@@ -3,8 +3,10 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -65,28 +67,88 @@ func (s *SerializedResource) Bytes() []byte {
 	return s.data
 }
 
+// ApplyOutcome describes what happened to a resource in the cluster when it
+// was applied.
+type ApplyOutcome string
+
+// These are the possible outcomes of ApplyAndReport.
+const (
+	ApplyCreated   ApplyOutcome = "created"   // the resource did not exist before
+	ApplyUpdated   ApplyOutcome = "updated"   // the resource existed and was changed
+	ApplyUnchanged ApplyOutcome = "unchanged" // the resource existed and already matched
+)
+
+// ApplyAndReport applies a SerializedResource to the cluster and reports
+// whether it was created, updated, or left unchanged. When dryRun is set, the
+// apply is only run on the server as a dry run and nothing is persisted.
+func (s *SerializedResource) ApplyAndReport(
+	ctx context.Context,
+	force bool,
+	dryRun bool,
+) (ApplyOutcome, error) {
+	before, err := s.dr.Get(ctx, s.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		before = nil
+	} else if err != nil {
+		return "", fmt.Errorf("dr.Get(%q, %q, %q): %w", s.ns, s.name, s.gvk.Kind, err)
+	}
+
+	after, err := s.patch(ctx, force, dryRun)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case before == nil:
+		return ApplyCreated, nil
+	case reflect.DeepEqual(comparableObject(before), comparableObject(after)):
+		return ApplyUnchanged, nil
+	default:
+		return ApplyUpdated, nil
+	}
+}
+
+// comparableObject returns the content of the object without the metadata the
+// server changes on every apply, even when nothing else changes.
+func comparableObject(un *unstructured.Unstructured) map[string]any {
+	obj := un.DeepCopy()
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	return obj.Object
+}
+
 // Apply applies a SerializedResource to the cluster.
 func (s *SerializedResource) Apply(
 	ctx context.Context,
 	force bool,
 ) error {
-	log.Linef("PATCH", "Patching from unstructured %q / %q", s.ns, s.name)
-	log.LineBytes("PATCH-DATA", s.data)
+	_, err := s.patch(ctx, force, false)
+	return err
+}
 
-	_, err := s.dr.Patch(
-		ctx,
-		s.name,
-		types.ApplyPatchType,
-		s.data,
-		metav1.PatchOptions{
-			Force:        &force,
-			FieldManager: FieldManagerGenifest,
-		},
-	)
+// patch server-side applies the resource and returns the resulting object.
+// When dryRun is set, the server only runs a dry run and persists nothing.
+func (s *SerializedResource) patch(
+	ctx context.Context,
+	force bool,
+	dryRun bool,
+) (*unstructured.Unstructured, error) {
+	opts := metav1.PatchOptions{
+		Force:        &force,
+		FieldManager: FieldManagerGenifest,
+	}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+
+	log.Linef("PATCH", "Patching from unstructured %q / %q (dry run %t)", s.ns, s.name, dryRun)
+	log.LineBytes("PATCH-DATA", s.data)
 
+	after, err := s.dr.Patch(ctx, s.name, types.ApplyPatchType, s.data, opts)
 	if err != nil {
-		return fmt.Errorf("dr.Patch(%q, %q, %q): %w", s.ns, s.name, s.gvk.Kind, err)
+		return nil, fmt.Errorf("dr.Patch(%q, %q, %q): %w", s.ns, s.name, s.gvk.Kind, err)
 	}
 
-	return nil
+	return after, nil
 }
@@ -3,12 +3,20 @@ package config
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 
 	cfgstr "github.com/zostay/genifest/pkg/strtools"
+	"github.com/zostay/genifest/pkg/tmpltools"
 )
 
+// DefaultMaxEvaluationDepth is the default limit on how deeply applyTemplate
+// may be nested.
+const DefaultMaxEvaluationDepth = 100
+
 // Config defines configuration for the cluster.
 type Config struct {
 	// CloudHome is the absolute path to the root of the configuration.
@@ -17,6 +25,43 @@ type Config struct {
 	// Clusters defines the orchestration configuration for each cluster managed
 	// by this configuration.
 	Clusters map[string]Cluster
+
+	// CacheDir is the directory used to cache values fetched from the network
+	// by template functions. Defaults to "cache" within CloudHome.
+	CacheDir string `mapstructure:"cache_dir"`
+
+	// CacheTTL is how long cached values are used before being fetched again.
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+
+	// NoCache disables the cache of values fetched from the network.
+	NoCache bool `mapstructure:"no_cache"`
+
+	// ProjectName names the project managed by this configuration.
+	ProjectName string `mapstructure:"project_name"`
+
+	// Vars defines project-wide constants that may be read with the configVar
+	// template function.
+	Vars map[string]any
+
+	// MaxDepth limits how deeply applyTemplate may be nested. Defaults to
+	// DefaultMaxEvaluationDepth.
+	MaxDepth int `mapstructure:"max_evaluation_depth"`
+
+	// Snippets are named templates that may be expanded in any source file with
+	// the snippet template function.
+	Snippets map[string]string
+
+	// StateFile is the file used to keep values stored by the state template
+	// function between runs. Defaults to "state.yaml" within CloudHome.
+	StateFile string `mapstructure:"state_file"`
+
+	// ReadOnlyState prevents the state template function from storing new
+	// values, for commands that only inspect what would be generated.
+	ReadOnlyState bool `mapstructure:"-"`
+
+	// Warnings lists the problems encountered while loading the configuration
+	// that did not prevent it from loading.
+	Warnings []string `mapstructure:"-"`
 }
 
 // Cluster configures orchestration of a single cluster.
@@ -60,6 +105,19 @@ type Cluster struct {
 
 	// Ghost is the ghost configuration to use.
 	Ghost Ghost
+
+	// IgnoreMarker is the text that, when found in the first few lines of a
+	// source file, causes genifest to skip that file. Defaults to
+	// "# genifest: ignore".
+	IgnoreMarker string `mapstructure:"ignore_marker"`
+
+	// KustomizeResources limits the source files in any directory containing a
+	// kustomization file to those listed in its resources and patches.
+	KustomizeResources bool `mapstructure:"kustomize_resources"`
+
+	// Vars defines constants for this cluster read with the configVar template
+	// function. These take precedence over the Vars of the Config.
+	Vars map[string]any
 }
 
 // Limits defines the allowlists and blocklists that identify resources the
@@ -114,9 +172,22 @@ type AutoDNS struct {
 	File string
 }
 
-func InitConfig(cfgFile string) (*Config, error) {
+// InitConfig loads the configuration from the given file. If no file is given,
+// clusters.yaml is searched for in /etc, /app, the working directory, and then
+// the root directory found by walking up from the working directory to the
+// nearest directory containing rootMarker (DefaultRootMarker, if empty). When
+// the configuration is loaded from that root, it also becomes the default cloud
+// home. When strict is set, any setting in the configuration file that does
+// not match a field of the configuration is an error. Settings merged in from
+// /etc/clusters-secrets.yaml are not checked, since other tools use it too.
+func InitConfig(cfgFile, rootMarker string, strict bool) (*Config, error) {
 	var config Config
 
+	if rootMarker == "" {
+		rootMarker = DefaultRootMarker
+	}
+
+	root, foundRoot := FindRoot(".", rootMarker)
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
 	} else {
@@ -125,6 +196,9 @@ func InitConfig(cfgFile string) (*Config, error) {
 		viper.AddConfigPath("/etc")
 		viper.AddConfigPath("/app")
 		viper.AddConfigPath(".")
+		if foundRoot {
+			viper.AddConfigPath(root)
+		}
 	}
 
 	viper.SetEnvPrefix("genifest")
@@ -133,13 +207,31 @@ func InitConfig(cfgFile string) (*Config, error) {
 		return &config, fmt.Errorf("Error reading in clusters.yaml: %w", err)
 	}
 
+	// the root is only the cloud home when the configuration came from there
+	usedRoot := false
+	if foundRoot && cfgFile == "" {
+		used, err := filepath.Abs(viper.ConfigFileUsed())
+		usedRoot = err == nil && filepath.Dir(used) == root
+	}
+
+	// the secret config is shared with other tools, so only the main
+	// configuration is checked for unknown settings
+	if strict {
+		var check Config
+		if err := viper.UnmarshalExact(&check); err != nil {
+			return &config, err
+		}
+	}
+
 	// separate file for secret config in production
+	var warnings []string
 	viper.SetConfigFile("/etc/clusters-secrets.yaml")
 	if err := viper.MergeInConfig(); err != nil {
 		const errPre = "Error merging in clusters-secrets.yaml"
 
 		// Make sure there's a warning recorded
 		fmt.Fprintf(os.Stderr, "WARN %s: %v\n", errPre, err)
+		warnings = append(warnings, fmt.Sprintf("%s: %v", errPre, err))
 	}
 
 	err := viper.Unmarshal(&config)
@@ -147,13 +239,92 @@ func InitConfig(cfgFile string) (*Config, error) {
 		return &config, err
 	}
 
+	config.Warnings = warnings
+
+	if config.CloudHome == "" && usedRoot {
+		config.CloudHome = root
+	}
+
 	return &config, nil
 }
 
+// Cache returns the cache to use for values fetched from the network by
+// template functions.
+func (c *Config) Cache() *tmpltools.Cache {
+	dir := c.CacheDir
+	if dir == "" {
+		dir = "cache"
+	}
+
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(c.CloudHome, dir)
+	}
+
+	return &tmpltools.Cache{
+		Dir:      dir,
+		TTL:      c.CacheTTL,
+		Disabled: c.NoCache,
+	}
+}
+
+// MaxEvaluationDepth returns the limit on how deeply applyTemplate may be
+// nested.
+func (c *Config) MaxEvaluationDepth() int {
+	if c.MaxDepth > 0 {
+		return c.MaxDepth
+	}
+
+	return DefaultMaxEvaluationDepth
+}
+
+// State returns the state file used to keep values between runs.
+func (c *Config) State() *tmpltools.StateFile {
+	path := c.StateFile
+	if path == "" {
+		path = "state.yaml"
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(c.CloudHome, path)
+	}
+
+	return &tmpltools.StateFile{Path: path, ReadOnly: c.ReadOnlyState}
+}
+
 func (c *Config) Tools(cluster *Cluster, noApi bool) *LazyTools {
 	return &LazyTools{cf: c, c: cluster, noApi: noApi}
 }
 
+// Var returns the value of the named variable, preferring the Vars of the
+// cluster over those of the configuration. Variable names are not case
+// sensitive. It returns false if no such variable is defined.
+func (c *Config) Var(cluster *Cluster, name string) (any, bool) {
+	name = strings.ToLower(name)
+	for _, vars := range []map[string]any{cluster.Vars, c.Vars} {
+		for k, v := range vars {
+			if strings.ToLower(k) == name {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// FilesRoot returns the directory used to locate files loaded by the file
+// template function.
+func (c *Cluster) FilesRoot(cloudHome string) string {
+	if c.FilesDir == "" {
+		return filepath.Join(cloudHome, "files")
+	}
+
+	if filepath.IsAbs(c.FilesDir) {
+		return c.FilesDir
+	}
+
+	return filepath.Join(cloudHome, c.FilesDir)
+}
+
 func makeSet(list []string) map[string]struct{} {
 	m := make(map[string]struct{}, len(list))
 	for _, k := range list {
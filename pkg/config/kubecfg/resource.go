@@ -3,10 +3,13 @@ package kubecfg
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"text/template"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -20,6 +23,7 @@ import (
 type Client struct {
 	cloudHome string
 	funcMap   template.FuncMap
+	snippets  map[string]string
 }
 
 // ResourceOptions encapsulates operational options associated with a resource
@@ -80,6 +84,28 @@ func (c *Client) SetFuncMap(
 	c.funcMap = funcMap
 }
 
+// SetSnippets sets the named template snippets that may be expanded while
+// templating. Snippet names are not case sensitive.
+func (c *Client) SetSnippets(snippets map[string]string) {
+	c.snippets = make(map[string]string, len(snippets))
+	for name, src := range snippets {
+		c.snippets[strings.ToLower(name)] = src
+	}
+}
+
+// FuncMap returns the function map associated with the Client, along with the
+// functions TemplateConfigFile binds to each file it templates.
+func (c *Client) FuncMap() template.FuncMap {
+	funcMap := make(template.FuncMap, len(c.funcMap)+2)
+	for name, f := range c.funcMap {
+		funcMap[name] = f
+	}
+	for name, f := range c.fileFuncMap("", template.New("")) {
+		funcMap[name] = f
+	}
+	return funcMap
+}
+
 // SetFunc modifies the function map associated with the Client to replace or
 // add another function to it.
 func (c *Client) SetFunc(
@@ -104,6 +130,12 @@ func (c *Client) ReadResourceFile(rfile string) ([]RawResource, error) {
 		return nil, err
 	}
 
+	return SplitResources(res), nil
+}
+
+// SplitResources breaks the given data into parts by the triple hyphen
+// separator, dropping parts that consist entirely of blank lines or comments.
+func SplitResources(res []byte) []RawResource {
 	sres := bytes.Split(res, []byte("\n---"))
 	fres := make([]RawResource, 0, len(sres))
 	for _, s := range sres {
@@ -132,7 +164,7 @@ func (c *Client) ReadResourceFile(rfile string) ([]RawResource, error) {
 		fres = append(fres, fo)
 	}
 
-	return fres, nil
+	return fres
 }
 
 // ParseResource parses a single resource. This MUST already be broken up from
@@ -143,10 +175,74 @@ func ParseResource(data []byte) (*unstructured.Unstructured, error) {
 
 	var uns unstructured.Unstructured
 	err := dec.Decode(&uns)
+	if err != nil {
+		if line := tabIndentedLine(data); line > 0 {
+			return &uns, fmt.Errorf("line %d of the document uses a tab for indentation, which YAML forbids: %w", line, err)
+		}
+	}
 
 	return &uns, err
 }
 
+// tabIndentedLine returns the 1-based number of the first line whose leading
+// whitespace contains a tab or 0 if there is no such line.
+func tabIndentedLine(data []byte) int {
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]
+		if bytes.IndexByte(indent, '\t') >= 0 && len(indent) < len(line) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// ResourceFileDiffers returns true if the named resource file does not exist or
+// if its current content differs from the given bytes.
+func (c *Client) ResourceFileDiffers(
+	wfile string,
+	bs []byte,
+) (bool, error) {
+	configPath := wfile
+	if !filepath.IsAbs(wfile) {
+		configPath = filepath.Join(c.cloudHome, wfile)
+	}
+
+	cur, err := os.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("os.ReadFile(%q): %w", configPath, err)
+	}
+
+	return !bytes.Equal(cur, bs), nil
+}
+
+// UpdateResourceFile writes out a resource to a configuration file, but only if
+// the file does not already contain exactly the same bytes. An unchanged file
+// is left untouched, so its modification time and permissions are preserved.
+// It returns true if the file was written.
+func (c *Client) UpdateResourceFile(
+	wfile string,
+	bs []byte,
+) (bool, error) {
+	changed, err := c.ResourceFileDiffers(wfile, bs)
+	if err != nil {
+		return false, err
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	err = c.WriteResourceFile(wfile, bs)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // WriteResourceFile writes out a resource to a configuration file.
 func (c *Client) WriteResourceFile(
 	wfile string,
@@ -0,0 +1,58 @@
+package kubecfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/config/kubecfg"
+)
+
+func TestClient_UpdateResourceFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	c := kubecfg.New(root)
+
+	// the trailing newline and document separator must survive untouched
+	res := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\n---\n")
+
+	written, err := c.UpdateResourceFile("deploy/app/test.yaml", res)
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	wfile := filepath.Join(root, "deploy", "app", "test.yaml")
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	err = os.Chtimes(wfile, old, old)
+	assert.NoError(t, err)
+
+	written, err = c.UpdateResourceFile("deploy/app/test.yaml", res)
+	assert.NoError(t, err)
+	assert.False(t, written)
+
+	bs, err := os.ReadFile(wfile)
+	assert.NoError(t, err)
+	assert.Equal(t, res, bs)
+
+	fi, err := os.Stat(wfile)
+	assert.NoError(t, err)
+	assert.True(t, fi.ModTime().Equal(old))
+
+	written, err = c.UpdateResourceFile("deploy/app/test.yaml", append(res, '\n'))
+	assert.NoError(t, err)
+	assert.True(t, written)
+}
+
+func TestParseResource_TabIndentation(t *testing.T) {
+	t.Parallel()
+
+	_, err := kubecfg.ParseResource([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n\tname: test\n"))
+	assert.ErrorContains(t, err, "line 4 of the document uses a tab for indentation")
+
+	un, err := kubecfg.ParseResource([]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\ndata:\n  a: |\n    x\n    \ty\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "test", un.GetName())
+}
@@ -1,23 +1,44 @@
 package kubecfg
 
 import (
+	"fmt"
 	"strings"
 	"text/template"
 
 	"github.com/Masterminds/sprig/v3"
+
+	"github.com/zostay/genifest/pkg/tmpltools"
 )
 
+// snippetPrefix keeps the names of snippet templates apart from templates
+// defined within the file being templated.
+const snippetPrefix = "snippet:"
+
 // TODO Look into minimizing or eliminating the need for templating here. We may
 // be able to incorporate kustomize to do much of it and specialized annotations
 // to do the rest.
 
 // TemplateConfigFile takes the given template string and templates the file as
-// a configuration. It returns the output of the templating.
+// a configuration. It returns the output of the templating. The name is made
+// available to the template through the sourcePath function.
+//
+// Snippets set on the client may be expanded with the snippet function, which
+// takes the name of the snippet and the data to execute it with, usually built
+// with dict.
 func (c *Client) TemplateConfigFile(name string, data []byte) (string, error) {
 	tmpl := template.New(name)
 	tmpl.Delims("{{{", "}}}")
 	tmpl.Funcs(c.funcMap)
+	tmpl.Funcs(c.fileFuncMap(name, tmpl))
 	tmpl.Funcs(sprig.TxtFuncMap())
+
+	for sn, src := range c.snippets {
+		_, err := tmpl.New(snippetPrefix + sn).Parse(src)
+		if err != nil {
+			return "", fmt.Errorf("snippet %q: %w", sn, err)
+		}
+	}
+
 	_, err := tmpl.Parse(string(data))
 	if err != nil {
 		return "", err
@@ -31,3 +52,38 @@ func (c *Client) TemplateConfigFile(name string, data []byte) (string, error) {
 
 	return res.String(), err
 }
+
+// fileFuncMap returns the template functions bound to the file being
+// templated, which is named name and parsed into tmpl.
+func (c *Client) fileFuncMap(name string, tmpl *template.Template) template.FuncMap {
+	var expanding []string
+	return template.FuncMap{
+		"sourcePath": func(part string) (string, error) {
+			return tmpltools.SourcePath(c.cloudHome, name, part)
+		},
+		"snippet": func(snippet string, data any) (string, error) {
+			snippet = strings.ToLower(snippet)
+			for _, s := range expanding {
+				if s == snippet {
+					return "", fmt.Errorf("recursive snippet expansion: %s -> %s",
+						strings.Join(expanding, " -> "), snippet)
+				}
+			}
+
+			st := tmpl.Lookup(snippetPrefix + snippet)
+			if st == nil {
+				return "", fmt.Errorf("no snippet named %q", snippet)
+			}
+
+			expanding = append(expanding, snippet)
+			defer func() { expanding = expanding[:len(expanding)-1] }()
+
+			res := new(strings.Builder)
+			if err := st.Execute(res, data); err != nil {
+				return "", err
+			}
+
+			return res.String(), nil
+		},
+	}
+}
@@ -0,0 +1,45 @@
+package kubecfg_test
+
+import (
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/config/kubecfg"
+)
+
+func TestClient_FuncMap(t *testing.T) {
+	t.Parallel()
+
+	c := kubecfg.New(t.TempDir())
+	c.SetFuncMap(template.FuncMap{"hello": func() string { return "hello" }})
+
+	funcMap := c.FuncMap()
+	assert.Contains(t, funcMap, "hello")
+	assert.Contains(t, funcMap, "sourcePath")
+	assert.Contains(t, funcMap, "snippet")
+}
+
+func TestClient_TemplateConfigFile_Snippets(t *testing.T) {
+	t.Parallel()
+
+	c := kubecfg.New(t.TempDir())
+	c.SetFuncMap(template.FuncMap{})
+	c.SetSnippets(map[string]string{
+		"Greeting": `hello {{{ .name }}}`,
+		"loopA":    `{{{ snippet "loopB" . }}}`,
+		"loopB":    `{{{ snippet "loopA" . }}}`,
+	})
+
+	// snippet names are not case sensitive
+	out, err := c.TemplateConfigFile("app.yaml", []byte(`{{{ snippet "greeting" (dict "name" "world") }}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", out)
+
+	_, err = c.TemplateConfigFile("app.yaml", []byte(`{{{ snippet "loopA" nil }}}`))
+	assert.ErrorContains(t, err, "recursive snippet expansion: loopa -> loopb -> loopa")
+
+	_, err = c.TemplateConfigFile("app.yaml", []byte(`{{{ snippet "missing" nil }}}`))
+	assert.ErrorContains(t, err, `no snippet named "missing"`)
+}
@@ -2,8 +2,9 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"io/fs"
 	"strings"
 	"text/template"
 
@@ -20,8 +21,10 @@ type LazyTools struct {
 	cf *Config
 	c  *Cluster
 
-	kube *k8s.Client
-	iam  *iam.Client
+	kube   *k8s.Client
+	iam    *iam.Client
+	values *tmpltools.ValuesFiles
+	git    *tmpltools.Git
 
 	noApi bool
 }
@@ -59,6 +62,7 @@ func (t *LazyTools) IAM() (*iam.Client, error) {
 func (t *LazyTools) ResMgr(ctx context.Context, skipSecrets bool) (*k8scfg.Client, error) {
 	rmgr := k8scfg.New(t.cf.CloudHome)
 	rmgr.SetFuncMap(t.makeFuncMap(ctx, rmgr, skipSecrets))
+	rmgr.SetSnippets(t.cf.Snippets)
 	return rmgr, nil
 }
 
@@ -79,37 +83,155 @@ func (t *LazyTools) makeFuncMap(
 		KeeperName: t.c.Ghost.Keeper,
 	}
 
-	filesRoot := t.cf.CloudHome
-	if filesDir := t.c.FilesDir; filesDir != "" {
-		if strings.HasPrefix(filesDir, "/") {
-			filesRoot = filesDir
-		} else {
-			filesRoot = filepath.Join(filesRoot, filesDir)
+	filesRoot := t.c.FilesRoot(t.cf.CloudHome)
+
+	file := func(app any, path string) (string, error) {
+		if app, ok := app.(string); ok {
+			return tmpltools.File(filesRoot, app, path)
+		}
+
+		apps, err := tmpltools.Apps(app)
+		if err != nil {
+			return "", err
+		}
+
+		return tmpltools.FileFirst(filesRoot, apps, path)
+	}
+
+	fileOptional := func(app any, path string) (string, error) {
+		data, err := file(app, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
 		}
-	} else {
-		filesRoot = filepath.Join(filesRoot, "files")
+
+		return data, err
 	}
 
-	file := func(app, path string) (string, error) {
-		return tmpltools.File(filesRoot, app, path)
+	fileConcat := func(app any, separator string, paths ...string) (string, error) {
+		parts := make([]string, len(paths))
+		for i, path := range paths {
+			var err error
+			parts[i], err = file(app, path)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		return strings.Join(parts, separator), nil
 	}
 
+	if t.values == nil {
+		t.values = &tmpltools.ValuesFiles{Root: filesRoot}
+	}
+
+	if t.git == nil {
+		t.git = &tmpltools.Git{Dir: t.cf.CloudHome}
+	}
+	t.git.Context = ctx
+
+	fileDocument := func(app any, path string, index int) (string, error) {
+		data, err := file(app, path)
+		if err != nil {
+			return "", err
+		}
+
+		docs := k8scfg.SplitResources([]byte(data))
+		if index < 0 || index >= len(docs) {
+			return "", fmt.Errorf("document index %d out of range, %q has %d documents", index, path, len(docs))
+		}
+
+		return string(docs[index].Config), nil
+	}
+
+	var applying []string
 	applyTemplate := func(name, data string) (string, error) {
+		if limit := t.cf.MaxEvaluationDepth(); len(applying) >= limit {
+			return "", fmt.Errorf("applyTemplate nested more than %d deep: %s -> %s",
+				limit, strings.Join(applying, " -> "), name)
+		}
+
+		applying = append(applying, name)
+		defer func() { applying = applying[:len(applying)-1] }()
+
 		return rmgr.TemplateConfigFile(name, []byte(data))
 	}
 
+	kubeContext := func() (string, error) {
+		if t.noApi {
+			return "", fmt.Errorf("no k8s API access")
+		}
+
+		if t.c.Context != "" {
+			return t.c.Context, nil
+		}
+
+		return k8s.CurrentContext()
+	}
+
+	cache := t.cf.Cache()
+	ddbLookup := func(table, field string, key map[string]any) (string, error) {
+		return cache.Get(func() (string, error) {
+			return aws.DDBLookup(table, field, key)
+		}, "ddbLookup", aws.Region, table, field, fmt.Sprint(key))
+	}
+
+	describeEfsFileSystemId := func(token string) (string, error) {
+		return cache.Get(func() (string, error) {
+			return aws.DescribeEfsFileSystemId(token)
+		}, "awsDescribeEfsFileSystemId", aws.Region, token)
+	}
+
+	sshKnownHost := func(name string) (string, error) {
+		return cache.Get(func() (string, error) {
+			return tmpltools.SSHKnownHost(ctx, name)
+		}, "sshKnownHost", name)
+	}
+
+	configVar := func(name string) (any, error) {
+		v, ok := t.cf.Var(t.c, name)
+		if !ok {
+			return nil, fmt.Errorf("no variable named %q in vars", name)
+		}
+
+		return v, nil
+	}
+
+	projectName := func() string {
+		return t.cf.ProjectName
+	}
+
+	kubeseal := func(ns, name, secret string) (string, error) {
+		return tmpltools.KubeSeal(ctx, ns, name, secret)
+	}
+
 	fm := template.FuncMap{
 		"tomlize":                    tmpltools.Tomlize,
 		"secretDict":                 ghost.SecretDict,
-		"ddbLookup":                  aws.DDBLookup,
-		"awsDescribeEfsFileSystemId": aws.DescribeEfsFileSystemId,
+		"ddbLookup":                  ddbLookup,
+		"awsDescribeEfsFileSystemId": describeEfsFileSystemId,
 		"awsDescribeEfsMountTargets": aws.DescribeEfsMountTargets,
 		"sshKey":                     tmpltools.SSHKey,
-		"sshKnownHost":               tmpltools.SSHKnownHost,
+		"sshKnownHost":               sshKnownHost,
 		"file":                       file,
+		"fileOptional":               fileOptional,
+		"fileDocument":               fileDocument,
+		"fileConcat":                 fileConcat,
+		"fileValue":                  t.values.Value,
 		"applyTemplate":              applyTemplate,
 		"zostaySecret":               ghost.Secret,
-		"kubeseal":                   tmpltools.KubeSeal,
+		"kubeseal":                   kubeseal,
+		"formDecode":                 tmpltools.FormDecode,
+		"regexReplaceFirst":          tmpltools.RegexReplaceFirst,
+		"blockScalar":                tmpltools.BlockScalar,
+		"kubeContext":                kubeContext,
+		"mustEnvExpand":              tmpltools.MustEnvExpand,
+		"state":                      t.cf.State().Value,
+		"git":                        t.git.Ref,
+		"configVar":                  configVar,
+		"projectName":                projectName,
+		"toYaml":                     tmpltools.ToYaml,
+		"toYamlIndent":               tmpltools.ToYamlIndent,
+		"toJsonIndent":               tmpltools.ToJsonIndent,
 	}
 
 	if skipSecrets {
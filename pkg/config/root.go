@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// DefaultRootMarker is the file that marks the root of the configuration when
+// no other marker is given.
+const DefaultRootMarker = "clusters.yaml"
+
+// FindRoot walks up from the given directory looking for the nearest directory
+// containing the marker file, much like git locates the root of a repository.
+// It returns the directory found or false if no directory up to the root of
+// the filesystem contains the marker.
+func FindRoot(dir, marker string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return dir, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+
+		dir = parent
+	}
+}
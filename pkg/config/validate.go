@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Validate checks the merged configuration for problems that can only be seen
+// once all the configuration has been loaded, such as directories that do not
+// exist. Every problem found is reported in the returned error.
+func (c *Config) Validate() error {
+	names := make([]string, 0, len(c.Clusters))
+	for name := range c.Clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		cluster := c.Clusters[name]
+
+		if cluster.SourceDir != "" {
+			if err := checkDir(c.CloudHome, cluster.SourceDir); err != nil {
+				errs = append(errs, fmt.Errorf("cluster %q source_dir: %w", name, err))
+			}
+		}
+
+		if cluster.FilesDir != "" {
+			if err := checkDir(c.CloudHome, cluster.FilesDir); err != nil {
+				errs = append(errs, fmt.Errorf("cluster %q files_dir: %w", name, err))
+			}
+		}
+
+		for _, m := range cluster.Limits.NotResourceFilesMatches() {
+			if !doublestar.ValidatePattern(m) {
+				errs = append(errs, fmt.Errorf("cluster %q not_resources: invalid pattern %q", name, m))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkDir returns an error if the given directory, relative to cloudHome if
+// not absolute, does not exist or is not a directory.
+func checkDir(cloudHome, dir string) error {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(cloudHome, dir)
+	}
+
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return err
+	}
+
+	if !fi.IsDir() {
+		return fmt.Errorf("%q is not a directory", dir)
+	}
+
+	return nil
+}
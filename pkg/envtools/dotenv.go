@@ -0,0 +1,80 @@
+package envtools
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseEnvFile reads KEY=VALUE pairs from the given reader. Blank lines and
+// lines starting with # are ignored. A leading "export " is permitted and values
+// may be wrapped in single or double quotes.
+func ParseEnvFile(r io.Reader) (map[string]string, error) {
+	env := map[string]string{}
+	lines := bufio.NewScanner(r)
+	lineNo := 0
+	for lines.Scan() {
+		lineNo++
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNo)
+		}
+
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing variable name", lineNo)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 {
+			if (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+		}
+
+		env[key] = value
+	}
+
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// LoadEnvFile reads the named dotenv file and sets each variable in the process
+// environment. Variables that are already set are left alone unless override
+// is true.
+func LoadEnvFile(path string, override bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("os.Open(%q): %w", path, err)
+	}
+	defer f.Close()
+
+	env, err := ParseEnvFile(f)
+	if err != nil {
+		return fmt.Errorf("ParseEnvFile(%q): %w", path, err)
+	}
+
+	for k, v := range env {
+		if _, set := os.LookupEnv(k); set && !override {
+			continue
+		}
+
+		if err := os.Setenv(k, v); err != nil {
+			return fmt.Errorf("os.Setenv(%q): %w", k, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package envtools_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/envtools"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	t.Parallel()
+
+	env, err := envtools.ParseEnvFile(strings.NewReader(`
+# comment
+A=1
+export B = two
+C="three four"
+D='five'
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"A": "1",
+		"B": "two",
+		"C": "three four",
+		"D": "five",
+	}, env)
+
+	_, err = envtools.ParseEnvFile(strings.NewReader("NOPE\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffContext is the number of unchanged lines shown around each change by
+// LineDiff.
+const DiffContext = 3
+
+// diffOp is a single line of a diff, marked ' ' when unchanged, '-' when only
+// expected, or '+' when only actual.
+type diffOp struct {
+	kind     byte
+	line     string
+	exp, act int // 0-based line numbers before the line in each input
+}
+
+// splitLines breaks content into lines, without the line endings.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// diffOps computes the shortest edit from the expected to the actual lines
+// using their longest common subsequence.
+func diffOps(exp, act []string) []diffOp {
+	// lcs[i][j] is the length of the LCS of exp[i:] and act[j:]
+	lcs := make([][]int, len(exp)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(act)+1)
+	}
+	for i := len(exp) - 1; i >= 0; i-- {
+		for j := len(act) - 1; j >= 0; j-- {
+			if exp[i] == act[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(exp)+len(act))
+	i, j := 0, 0
+	for i < len(exp) || j < len(act) {
+		switch {
+		case i < len(exp) && j < len(act) && exp[i] == act[j]:
+			ops = append(ops, diffOp{' ', exp[i], i, j})
+			i++
+			j++
+		case i < len(exp) && (j == len(act) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', exp[i], i, j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', act[j], i, j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// hunkRange formats the start and length of a hunk for one side of the diff.
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// LineDiff returns a unified diff of the expected and actual content, without
+// the file headers. Every changed line is prefixed by "-" or "+" and up to
+// DiffContext unchanged lines are kept around each change. It returns an
+// empty string when the content is the same.
+func LineDiff(expected, actual string) string {
+	ops := diffOps(splitLines(expected), splitLines(actual))
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// find the next change
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+
+		// extend the hunk until the changes are separated by more than twice
+		// the context
+		end, unchanged := start, 0
+		for i := start; i < len(ops) && unchanged <= 2*DiffContext; i++ {
+			if ops[i].kind == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+				end = i + 1
+			}
+		}
+
+		from := max(start-DiffContext, 0)
+		to := min(end+DiffContext, len(ops))
+
+		expLen, actLen := 0, 0
+		for _, op := range ops[from:to] {
+			if op.kind != '+' {
+				expLen++
+			}
+			if op.kind != '-' {
+				actLen++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(ops[from].exp, expLen), hunkRange(ops[from].act, actLen))
+		for _, op := range ops[from:to] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.line)
+		}
+
+		start = to
+	}
+
+	return out.String()
+}
@@ -0,0 +1,59 @@
+package k8s_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/manager/k8s"
+)
+
+func TestLineDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		diff     string
+	}{
+		{
+			name:     "same",
+			expected: "a\nb\n",
+			actual:   "a\nb\n",
+			diff:     "",
+		},
+		{
+			name:     "changed line",
+			expected: "kind: Service\nmetadata:\n  name: api\nspec:\n  port: 80\n",
+			actual:   "kind: Service\nmetadata:\n  name: api\nspec:\n  port: 8080\n",
+			diff:     "@@ -2,4 +2,4 @@\n metadata:\n   name: api\n spec:\n-  port: 80\n+  port: 8080\n",
+		},
+		{
+			name:     "added file",
+			expected: "",
+			actual:   "a\nb\n",
+			diff:     "@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name:     "removed file",
+			expected: "a\n",
+			actual:   "",
+			diff:     "@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name:     "separate hunks",
+			expected: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			actual:   "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			diff: "@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+twelve\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.diff, k8s.LineDiff(tc.expected, tc.actual))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package k8s
+
+import "strings"
+
+// FileError is an error that occurred while generating resources from a
+// particular source file.
+type FileError struct {
+	File string
+	Err  error
+}
+
+// Error returns the message of the wrapped error, prefixed by the file.
+func (e *FileError) Error() string {
+	return e.File + ": " + e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// GenerateError collects all the errors that occurred during a call to
+// GenerateK8sResources.
+type GenerateError struct {
+	Errs []*FileError
+}
+
+// Error lists the message of every error collected.
+func (e *GenerateError) Error() string {
+	ss := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		ss[i] = err.Error()
+	}
+	return "error during apply:\n    - " + strings.Join(ss, "\n    - ")
+}
+
+// Unwrap returns the collected errors.
+func (e *GenerateError) Unwrap() []error {
+	errs := make([]error, len(e.Errs))
+	for i, err := range e.Errs {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,23 @@
+package k8s_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/manager/k8s"
+)
+
+func TestGenerateError_Error(t *testing.T) {
+	t.Parallel()
+
+	err := &k8s.GenerateError{Errs: []*k8s.FileError{
+		{File: "src/app/a.yaml", Err: errors.New("bad template")},
+		{File: "src/app/b.yaml", Err: errors.New("stopped while processing")},
+	}}
+
+	assert.Equal(t, "error during apply:\n"+
+		"    - src/app/a.yaml: bad template\n"+
+		"    - src/app/b.yaml: stopped while processing", err.Error())
+}
@@ -2,7 +2,10 @@ package k8s
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -15,6 +18,80 @@ import (
 	"github.com/zostay/genifest/pkg/manager/k8scfg"
 )
 
+// Options modifies the way GenerateK8sResources works.
+type Options struct {
+	// SkipSecrets skips generating resources that contain secrets.
+	SkipSecrets bool
+
+	// DisableApi prevents any calls to the kubernetes API.
+	DisableApi bool
+
+	// ChangedOnly suppresses the progress output for source files whose
+	// generated resources did not change.
+	ChangedOnly bool
+
+	// SummaryOnly suppresses all progress output for source files.
+	SummaryOnly bool
+
+	// MaxFileSize is the size in bytes beyond which source files are skipped.
+	// No limit is applied when this is zero.
+	MaxFileSize int64
+
+	// Scope limits generation to source files found within this directory,
+	// relative to the source_dir of the cluster, when set.
+	Scope string
+
+	// FailOnEmpty treats a source file without any documents as an error
+	// rather than skipping it with a warning.
+	FailOnEmpty bool
+
+	// FailOnConflict treats two resources saved to the same resource file as an
+	// error rather than letting the last one win with a warning.
+	FailOnConflict bool
+
+	// KeepGoing processes the remaining documents of a source file after one
+	// of them fails, saving the resources of those that succeed.
+	KeepGoing bool
+
+	// Apply server-side applies every generated resource to the cluster after it
+	// is saved, whether or not its file changed. This requires API access.
+	Apply bool
+
+	// DryRunServer makes Apply a server-side dry run that changes nothing.
+	DryRunServer bool
+
+	// DeployDir replaces the deploy_dir of the cluster configuration as the
+	// place generated resources are saved when set.
+	DeployDir string
+}
+
+// Stats summarizes the work performed by GenerateK8sResources.
+type Stats struct {
+	Files         int // source files processed
+	FilesModified int // source files with at least one modified resource
+	Saved         int // resources generated and saved to resource files
+	Modified      int // resources whose saved file changed
+	Skipped       int // resources skipped due to limits
+	Created       int // resources created in the cluster by Apply
+	Updated       int // resources updated in the cluster by Apply
+	Unchanged     int // resources applied to the cluster that were unchanged
+
+	ModifiedFiles []string // paths of the resource files that were modified
+}
+
+// Add accumulates the given stats into these stats.
+func (s *Stats) Add(o Stats) {
+	s.Files += o.Files
+	s.FilesModified += o.FilesModified
+	s.Saved += o.Saved
+	s.Modified += o.Modified
+	s.Skipped += o.Skipped
+	s.Created += o.Created
+	s.Updated += o.Updated
+	s.Unchanged += o.Unchanged
+	s.ModifiedFiles = append(s.ModifiedFiles, o.ModifiedFiles...)
+}
+
 // GenerateK8sResources locates all the configuration file templates, renders
 // the templates to te deployment folder, and returns any errors that occurred
 // while doing it. This sets up deployment via gitops through ArgoCD.
@@ -23,9 +100,9 @@ func GenerateK8sResources(
 	cfg *config.Config,
 	cluster *config.Cluster,
 	match string,
-	skipSecrets bool,
-	disableApi bool,
-) error {
+	opts Options,
+) (Stats, error) {
+	var stats Stats
 	log.Line("TASK", "Generate deployment resource manifests from source templates.")
 
 	configFiles, err := k8scfg.ConfigFiles(
@@ -36,19 +113,30 @@ func GenerateK8sResources(
 		false,
 	)
 	if err != nil {
-		return fmt.Errorf("k8s.ConfigFiles: %w", err)
+		return stats, fmt.Errorf("k8s.ConfigFiles: %w", err)
 	}
 
-	tools := cfg.Tools(cluster, disableApi)
+	if opts.Scope != "" {
+		configFiles = scopeConfigFiles(cfg.CloudHome, cluster.SourceDir, opts.Scope, configFiles)
+	}
+
+	if cluster.KustomizeResources {
+		configFiles, err = k8scfg.KustomizeConfigFiles(configFiles)
+		if err != nil {
+			return stats, fmt.Errorf("k8scfg.KustomizeConfigFiles(): %w", err)
+		}
+	}
+
+	tools := cfg.Tools(cluster, opts.DisableApi)
 
 	var serializeResource func(un *unstructured.Unstructured) (*k8s.SerializedResource, error)
-	if disableApi {
+	if opts.DisableApi {
 		log.Line("SKIP", "Skipping API calls.")
 		serializeResource = k8scfg.SerializeResource
 	} else {
 		kc, err := tools.Kube()
 		if err != nil {
-			return fmt.Errorf("tools.Kube(): %w", err)
+			return stats, fmt.Errorf("tools.Kube(): %w", err)
 		}
 
 		serializeResource = kc.SerializeResource
@@ -56,22 +144,84 @@ func GenerateK8sResources(
 
 	allowedKind := cluster.Limits.KindsSet()
 	blockedNs := cluster.Limits.NotNamespacesSet()
-	errs := []error{}
+	var out io.Writer = os.Stdout
+	if opts.SummaryOnly {
+		out = io.Discard
+	}
+
+	deployDir := cluster.DeployDir
+	if opts.DeployDir != "" {
+		deployDir = opts.DeployDir
+	}
+
+	errs := []*FileError{}
+	savedFrom := map[string]string{} // resource file -> source file
+
+	// the source file most recently started
+	processing := ""
 	for _, pc := range configFiles {
+		// the context usually ends while the previous file is templated, so
+		// report that one as the file being processed
+		if err := ctx.Err(); err != nil {
+			if processing == "" {
+				errs = append(errs, &FileError{pc, fmt.Errorf("stopped before processing: %w", err)})
+			} else {
+				errs = append(errs, &FileError{processing, fmt.Errorf("stopped while processing: %w", err)})
+			}
+			break
+		}
+		processing = pc
+
 		appName := filepath.Base(filepath.Dir(pc))
-		appDir := filepath.Join(cluster.DeployDir, appName)
+		appDir := filepath.Join(deployDir, appName)
 
-		fmt.Printf("Generate %s (app %s): %s ... ", cluster.Context, appName, pc)
+		progress := fmt.Sprintf("Generate %s (app %s): %s ... ", cluster.Context, appName, pc)
+		if !opts.ChangedOnly {
+			fmt.Fprint(out, progress)
+		}
 
+		if opts.MaxFileSize > 0 {
+			fi, err := os.Stat(pc)
+			if err == nil && fi.Size() > opts.MaxFileSize {
+				log.Linef("WARN", "- Skipping %q because it is %d bytes, which exceeds the limit of %d bytes", pc, fi.Size(), opts.MaxFileSize)
+				if opts.ChangedOnly {
+					fmt.Fprint(out, progress)
+				}
+				fmt.Fprintf(out, "skipped, WARNING larger than %d bytes.\n", opts.MaxFileSize)
+				continue
+			}
+		}
+
+		// a failure here is reported when the file is processed below
+		ignored, err := k8scfg.HasIgnoreMarker(pc, cluster.IgnoreMarker)
+		if err != nil {
+			log.Linef("WARN", "Unable to check %q for the ignore marker: %v", pc, err)
+		}
+
+		if ignored {
+			log.Linef("SKIP", "- Ignoring %q because it has the ignore marker", pc)
+			if !opts.ChangedOnly {
+				fmt.Fprintln(out, "ignored.")
+			}
+			continue
+		}
+
+		stats.Files++
 		errsThisTime := 0
-		resources, err := k8scfg.ProcessResourceFile(ctx, tools, pc, skipSecrets)
+		resources, err := k8scfg.ProcessResourceFile(ctx, tools, pc, opts.SkipSecrets, opts.KeepGoing)
+		if errors.Is(err, k8scfg.ErrNoResources) && !opts.FailOnEmpty {
+			log.Linef("WARN", "- No resources found in %q", pc)
+			err = nil
+		}
 		if err != nil {
-			errs = append(errs, fmt.Errorf("k8scfg.ProcessResourceFile(): %w", err))
+			errs = append(errs, &FileError{pc, fmt.Errorf("k8scfg.ProcessResourceFile(): %w", err)})
 			errsThisTime++
-			resources = []kubecfg.Resource{}
+			if resources == nil {
+				resources = []kubecfg.Resource{}
+			}
 		}
 
-		skipped := 0
+		skipped, changed := 0, 0
 		for _, r := range resources {
 			// check limits
 			_, ok := allowedKind[r.Data.GetKind()]
@@ -88,17 +238,71 @@ func GenerateK8sResources(
 
 			sr, err := serializeResource(r.Data)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("kube.SerializeResource(): %w", err))
+				errs = append(errs, &FileError{pc, fmt.Errorf("kube.SerializeResource(): %w", err)})
 				errsThisTime++
 				continue
 			}
 
-			err = k8scfg.SaveResourceFile(ctx, tools, appDir, sr, skipSecrets)
+			wfile := k8scfg.ResourceFilePath(appDir, sr)
+			if prev, conflict := savedFrom[wfile]; conflict {
+				if opts.FailOnConflict {
+					errs = append(errs, &FileError{pc, fmt.Errorf("resource %s from %q conflicts with the one generated from %q", sr.ResourceID(), pc, prev)})
+					errsThisTime++
+					continue
+				}
+
+				log.LineAndSayf("WARN", "Resource %s from %q replaces the one generated from %q", sr.ResourceID(), pc, prev)
+			}
+			savedFrom[wfile] = pc
+
+			resChanged, err := k8scfg.SaveResourceFile(ctx, tools, appDir, sr, opts.SkipSecrets)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("k8scfg.SaveResourceFile(): %w", err))
+				errs = append(errs, &FileError{pc, fmt.Errorf("k8scfg.SaveResourceFile(): %w", err)})
 				errsThisTime++
 				continue
 			}
+
+			stats.Saved++
+			if resChanged {
+				stats.Modified++
+				stats.ModifiedFiles = append(stats.ModifiedFiles, wfile)
+				changed++
+			}
+
+			// the cluster may have drifted from the files, so apply every
+			// resource, not only those whose files changed
+			if opts.Apply {
+				outcome, err := sr.ApplyAndReport(ctx, false, opts.DryRunServer)
+				if err != nil {
+					errs = append(errs, &FileError{pc, fmt.Errorf("sr.ApplyAndReport(): %w", err)})
+					errsThisTime++
+					continue
+				}
+
+				log.Linef("APPLY", "- Resource %s %s in the cluster", sr.ResourceID(), outcome)
+				switch outcome {
+				case k8s.ApplyCreated:
+					stats.Created++
+				case k8s.ApplyUpdated:
+					stats.Updated++
+				case k8s.ApplyUnchanged:
+					stats.Unchanged++
+				}
+			}
+		}
+
+		stats.Skipped += skipped
+		if changed > 0 {
+			stats.FilesModified++
+		}
+
+		if opts.ChangedOnly {
+			if changed == 0 && errsThisTime == 0 {
+				log.Linef("SKIP", "- No changes generated from %q", pc)
+				continue
+			}
+
+			fmt.Fprint(out, progress)
 		}
 
 		switch {
@@ -106,30 +310,48 @@ func GenerateK8sResources(
 			switch {
 			case skipped == len(resources):
 				if errsThisTime > 0 {
-					fmt.Println("skipped with ERRORS (see below).")
+					fmt.Fprintln(out, "skipped with ERRORS (see below).")
 				} else {
-					fmt.Println("skipped.")
+					fmt.Fprintln(out, "skipped.")
 				}
 			case errsThisTime > 0:
-				fmt.Printf("done with ERRORS (see below), skipped %d of %d.\n",
+				fmt.Fprintf(out, "done with ERRORS (see below), skipped %d of %d.\n",
 					skipped, len(resources))
 			default:
-				fmt.Printf("done, skipped %d of %d.\n", skipped, len(resources))
+				fmt.Fprintf(out, "done, skipped %d of %d.\n", skipped, len(resources))
 			}
 		case errsThisTime > 0:
-			fmt.Println("ERRORS (see below).")
+			fmt.Fprintln(out, "ERRORS (see below).")
 		default:
-			fmt.Println("done.")
+			fmt.Fprintln(out, "done.")
 		}
 	}
 
 	if len(errs) > 0 {
-		ss := make([]string, len(errs))
-		for i, err := range errs {
-			ss[i] = err.Error()
+		return stats, &GenerateError{errs}
+	}
+
+	return stats, nil
+}
+
+// scopeConfigFiles returns only those config files found within the scope
+// directory, which is relative to the source directory.
+func scopeConfigFiles(cloudHome, sourceDir, scope string, configFiles []string) []string {
+	scopeDir := filepath.Join(sourceDir, scope)
+	if !filepath.IsAbs(scopeDir) {
+		scopeDir = filepath.Join(cloudHome, scopeDir)
+	}
+
+	scoped := make([]string, 0, len(configFiles))
+	for _, cf := range configFiles {
+		rel, err := filepath.Rel(scopeDir, cf)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			log.Linef("SKIP", "- Skipping %q because it is outside scope %q", cf, scope)
+			continue
 		}
-		return fmt.Errorf("error during apply:\n    - %s", strings.Join(ss, "\n    - "))
+
+		scoped = append(scoped, cf)
 	}
 
-	return nil
+	return scoped
 }
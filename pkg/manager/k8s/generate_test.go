@@ -0,0 +1,137 @@
+package k8s_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/config"
+	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/manager/k8s"
+)
+
+// configMap returns a source document for a config map with the given name and
+// data value.
+func configMap(name, value string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  value: " + value + "\n"
+}
+
+func TestGenerateK8sResources(t *testing.T) {
+	// generation is logged
+	assert.NoError(t, log.Setup("", "", true, false))
+
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sources map[string]string
+		opts    k8s.Options
+		saved   map[string]string // resource file -> data value
+		errs    []string
+	}{
+		{
+			name: "everything",
+			sources: map[string]string{
+				"web/a.yaml": configMap("a", "1"),
+				"api/b.yaml": configMap("b", "2"),
+			},
+			saved: map[string]string{
+				"web/default/v1/ConfigMap/a.yaml": "1",
+				"api/default/v1/ConfigMap/b.yaml": "2",
+			},
+		},
+		{
+			name: "scope",
+			sources: map[string]string{
+				"web/a.yaml":       configMap("a", "1"),
+				"api/b.yaml":       configMap("b", "2"),
+				"api-extra/c.yaml": configMap("c", "3"),
+			},
+			opts: k8s.Options{Scope: "api"},
+			saved: map[string]string{
+				"api/default/v1/ConfigMap/b.yaml": "2",
+			},
+		},
+		{
+			name: "conflict last wins",
+			sources: map[string]string{
+				"web/a.yaml": configMap("same", "1"),
+				"web/b.yaml": configMap("same", "2"),
+			},
+			saved: map[string]string{
+				"web/default/v1/ConfigMap/same.yaml": "2",
+			},
+		},
+		{
+			name: "conflict fails",
+			sources: map[string]string{
+				"web/a.yaml": configMap("same", "1"),
+				"web/b.yaml": configMap("same", "2"),
+			},
+			opts: k8s.Options{FailOnConflict: true},
+			saved: map[string]string{
+				"web/default/v1/ConfigMap/same.yaml": "1",
+			},
+			errs: []string{"src/web/a.yaml", "src/web/b.yaml", "conflicts"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			for name, content := range tc.sources {
+				path := filepath.Join(root, "src", name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			}
+
+			cfg := &config.Config{CloudHome: root}
+			cluster := &config.Cluster{SourceDir: "src"}
+
+			opts := tc.opts
+			opts.SkipSecrets = true
+			opts.DisableApi = true
+			opts.SummaryOnly = true
+			opts.DeployDir = filepath.Join(root, "deploy")
+
+			_, err := k8s.GenerateK8sResources(context.Background(), cfg, cluster, "", opts)
+			if len(tc.errs) > 0 {
+				for _, msg := range tc.errs {
+					assert.ErrorContains(t, err, msg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			saved := []string{}
+			err = filepath.WalkDir(opts.DeployDir, func(path string, d os.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(opts.DeployDir, path)
+				saved = append(saved, filepath.ToSlash(rel))
+				return err
+			})
+			assert.NoError(t, err)
+
+			expect := make([]string, 0, len(tc.saved))
+			for name, value := range tc.saved {
+				expect = append(expect, name)
+
+				bs, err := os.ReadFile(filepath.Join(opts.DeployDir, name))
+				assert.NoError(t, err)
+				assert.Contains(t, string(bs), `"value":`+value, name)
+			}
+			sort.Strings(expect)
+			sort.Strings(saved)
+			assert.Equal(t, expect, saved, strings.Join(saved, ", "))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package k8s
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Difference describes a single file that differs between an expected and an
+// actual directory of generated resources.
+type Difference struct {
+	// Path is the path to the file relative to the compared directories.
+	Path string
+
+	// Expected is the expected content or nil if the file was not expected.
+	Expected []byte
+
+	// Actual is the actual content or nil if the file was not generated.
+	Actual []byte
+}
+
+// CompareDirs compares every file found in the expected and actual directories
+// and returns the differences found, sorted by path. A missing expected
+// directory is treated as empty.
+func CompareDirs(expected, actual string) ([]Difference, error) {
+	expFiles, err := readTree(expected)
+	if err != nil {
+		return nil, fmt.Errorf("readTree(%q): %w", expected, err)
+	}
+
+	actFiles, err := readTree(actual)
+	if err != nil {
+		return nil, fmt.Errorf("readTree(%q): %w", actual, err)
+	}
+
+	diffs := []Difference{}
+	for path, exp := range expFiles {
+		act, ok := actFiles[path]
+		if !ok || !bytes.Equal(exp, act) {
+			diffs = append(diffs, Difference{path, exp, act})
+		}
+	}
+
+	for path, act := range actFiles {
+		if _, ok := expFiles[path]; !ok {
+			diffs = append(diffs, Difference{path, nil, act})
+		}
+	}
+
+	sort.Slice(diffs, func(a, b int) bool {
+		return diffs[a].Path < diffs[b].Path
+	})
+
+	return diffs, nil
+}
+
+// readTree reads every file below the given root into memory, keyed by the
+// path relative to the root.
+func readTree(root string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		files[rel], err = os.ReadFile(path)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
@@ -141,7 +141,7 @@ func rewriteAuth(
 				return nil, fmt.Errorf("iamc.RotateAccessKeyForUser(): %w", err)
 			}
 
-			aksr, err := k8s.MakeAccessKeySecretResource(ns, name, ak, sk)
+			aksr, err := k8s.MakeAccessKeySecretResource(ctx, ns, name, ak, sk)
 			if err != nil {
 				return nil, fmt.Errorf("k8s.MakeAccessKeySecretResource(): %w", err)
 			}
@@ -0,0 +1,41 @@
+package k8scfg
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultIgnoreMarker is the marker used to identify source files that
+	// genifest should leave alone when no other marker is configured.
+	DefaultIgnoreMarker = "# genifest: ignore"
+
+	// IgnoreMarkerLines is the number of lines at the top of a source file that
+	// are searched for the ignore marker.
+	IgnoreMarkerLines = 5
+)
+
+// HasIgnoreMarker returns true if one of the first few lines of the named file
+// contains the given marker.
+func HasIgnoreMarker(config, marker string) (bool, error) {
+	if marker == "" {
+		marker = DefaultIgnoreMarker
+	}
+
+	f, err := os.Open(config)
+	if err != nil {
+		return false, fmt.Errorf("os.Open(%q): %w", config, err)
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	for i := 0; i < IgnoreMarkerLines && lines.Scan(); i++ {
+		if strings.Contains(lines.Text(), marker) {
+			return true, nil
+		}
+	}
+
+	return false, lines.Err()
+}
@@ -0,0 +1,114 @@
+package k8scfg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zostay/genifest/pkg/log"
+)
+
+// KustomizationFiles are the names kustomize accepts for a kustomization file.
+var KustomizationFiles = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// kustomization holds the parts of a kustomization file that name files.
+type kustomization struct {
+	Resources []string `yaml:"resources"`
+	Patches   []struct {
+		Path string `yaml:"path"`
+	} `yaml:"patches"`
+	PatchesStrategicMerge []string `yaml:"patchesStrategicMerge"`
+}
+
+// isKustomizationFile returns true if the file is named like a kustomization
+// file.
+func isKustomizationFile(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range KustomizationFiles {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// readKustomization returns the set of files named by the kustomization file in
+// the given directory, or nil if the directory has no kustomization file.
+func readKustomization(dir string) (map[string]struct{}, error) {
+	for _, name := range KustomizationFiles {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("os.ReadFile(%q): %w", path, err)
+		}
+
+		var k kustomization
+		if err := yaml.Unmarshal(data, &k); err != nil {
+			return nil, fmt.Errorf("yaml.Unmarshal(%q): %w", path, err)
+		}
+
+		files := make([]string, 0, len(k.Resources)+len(k.Patches)+len(k.PatchesStrategicMerge))
+		files = append(files, k.Resources...)
+		for _, p := range k.Patches {
+			if p.Path != "" {
+				files = append(files, p.Path)
+			}
+		}
+		files = append(files, k.PatchesStrategicMerge...)
+
+		listed := make(map[string]struct{}, len(files))
+		for _, f := range files {
+			listed[filepath.Join(dir, f)] = struct{}{}
+		}
+
+		return listed, nil
+	}
+
+	return nil, nil
+}
+
+// KustomizeConfigFiles limits the config files to those listed in the
+// resources and patches of the kustomization file in the same directory.
+// Config files in a directory without a kustomization file are all kept, and
+// the kustomization files themselves are always dropped. A file in a
+// subdirectory is governed only by the kustomization file of that subdirectory.
+func KustomizeConfigFiles(configFiles []string) ([]string, error) {
+	listedByDir := map[string]map[string]struct{}{}
+	kept := make([]string, 0, len(configFiles))
+	for _, cf := range configFiles {
+		dir := filepath.Dir(cf)
+		listed, ok := listedByDir[dir]
+		if !ok {
+			var err error
+			listed, err = readKustomization(dir)
+			if err != nil {
+				return nil, err
+			}
+			listedByDir[dir] = listed
+		}
+
+		if listed == nil {
+			kept = append(kept, cf)
+			continue
+		}
+
+		if isKustomizationFile(cf) {
+			continue
+		}
+
+		if _, ok := listed[filepath.Clean(cf)]; !ok {
+			log.Linef("SKIP", "Skipping %q because its kustomization does not list it", cf)
+			continue
+		}
+
+		kept = append(kept, cf)
+	}
+
+	return kept, nil
+}
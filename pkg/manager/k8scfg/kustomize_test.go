@@ -0,0 +1,98 @@
+package k8scfg_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/manager/k8scfg"
+)
+
+func TestKustomizeConfigFiles(t *testing.T) {
+	t.Parallel()
+
+	// skipped files are logged
+	assert.NoError(t, log.Setup("", "", true, false))
+
+	tests := []struct {
+		name   string
+		files  map[string]string
+		expect []string
+	}{
+		{
+			name: "no kustomization",
+			files: map[string]string{
+				"app/deployment.yaml": "",
+				"app/service.yaml":    "",
+			},
+			expect: []string{"app/deployment.yaml", "app/service.yaml"},
+		},
+		{
+			name: "unlisted files dropped",
+			files: map[string]string{
+				"app/kustomization.yaml": "resources:\n- deployment.yaml\n",
+				"app/deployment.yaml":    "",
+				"app/service.yaml":       "",
+			},
+			expect: []string{"app/deployment.yaml"},
+		},
+		{
+			name: "kustomization file dropped",
+			files: map[string]string{
+				"app/Kustomization":   "resources:\n- deployment.yaml\n- Kustomization\n",
+				"app/deployment.yaml": "",
+			},
+			expect: []string{"app/deployment.yaml"},
+		},
+		{
+			name: "patches",
+			files: map[string]string{
+				"app/kustomization.yml": "patches:\n- path: replicas.yaml\n- patch: inline\n" +
+					"patchesStrategicMerge:\n- memory.yaml\n",
+				"app/replicas.yaml": "",
+				"app/memory.yaml":   "",
+				"app/other.yaml":    "",
+			},
+			expect: []string{"app/memory.yaml", "app/replicas.yaml"},
+		},
+		{
+			name: "subdirectory kustomization",
+			files: map[string]string{
+				"app/kustomization.yaml":         "resources:\n- deployment.yaml\n- overlay\n",
+				"app/deployment.yaml":            "",
+				"app/overlay/kustomization.yaml": "resources:\n- service.yaml\n",
+				"app/overlay/service.yaml":       "",
+				"app/overlay/ingress.yaml":       "",
+				"app/plain/configmap.yaml":       "",
+			},
+			expect: []string{"app/deployment.yaml", "app/overlay/service.yaml", "app/plain/configmap.yaml"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			configFiles := []string{}
+			for name, content := range tc.files {
+				path := filepath.Join(root, name)
+				assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+				assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+				configFiles = append(configFiles, path)
+			}
+
+			kept, err := k8scfg.KustomizeConfigFiles(configFiles)
+			assert.NoError(t, err)
+
+			expect := make([]string, len(tc.expect))
+			for i, name := range tc.expect {
+				expect[i] = filepath.Join(root, name)
+			}
+			assert.ElementsMatch(t, expect, kept)
+		})
+	}
+}
@@ -11,6 +11,10 @@ import (
 
 var ErrSecret = errors.New("SKIP SECRET")
 
+// ErrNoResources is returned by ProcessResourceFile when the resource file does
+// not contain any documents.
+var ErrNoResources = errors.New("no resources found")
+
 var Rewriters = []RewriteRoutine{
 	RewriteDeploymentAuth,
 	RewriteCronJobAuth,
@@ -21,12 +25,17 @@ var Rewriters = []RewriteRoutine{
 // and then the result is returned as a slice of Resource objects, which contain
 // the parsed resource and any other options.
 //
-// Returns an error if any of this fails.
+// Returns an error if any of this fails. Returns ErrNoResources if the file
+// contains no documents at all. When keepGoing is set, a document that fails
+// does not stop the rest from being processed; the resources of the documents
+// that succeeded are returned along with an error naming each document index
+// that failed.
 func ProcessResourceFile(
 	ctx context.Context,
 	tools Tools,
 	config string,
 	skipSecrets bool,
+	keepGoing bool,
 ) ([]k8scfg.Resource, error) {
 	c, err := tools.ResMgr(ctx, skipSecrets)
 	if err != nil {
@@ -38,29 +47,56 @@ func ProcessResourceFile(
 		return nil, fmt.Errorf("c.ReadResourceFile(): %w", err)
 	}
 
+	if len(cfs) == 0 {
+		return nil, ErrNoResources
+	}
+
 	ress := make([]k8scfg.Resource, 0, len(cfs))
-	for _, cf := range cfs {
-		res, err := c.TemplateConfigFile(config, cf.Config)
+	var docErrs []error
+	for i, cf := range cfs {
+		routs, err := processResource(ctx, tools, c, config, cf, skipSecrets)
 		if err != nil {
-			if skipSecrets && errors.Is(err, ErrSecret) {
-				// just ignore this and keep going
-				log.Linef("SKIP", "Skip templating a resource in %q because it contains a secret.", config)
-				continue
+			if !keepGoing {
+				return nil, err
 			}
-			return nil, fmt.Errorf("c.TemplateConfigFile(): %w", err)
-		}
 
-		rewriteOpt := RewriteOptions{
-			SkipSecrets: skipSecrets,
-		}
-		routs, err := RewriteConfigFile(
-			ctx, tools, res, cf.ResourceOptions, Rewriters, &rewriteOpt)
-		if err != nil {
-			return nil, fmt.Errorf("c.RewriteConfigFile(): %w", err)
+			docErrs = append(docErrs, fmt.Errorf("document %d: %w", i, err))
+			continue
 		}
 
 		ress = append(ress, routs...)
 	}
 
-	return ress, nil
+	return ress, errors.Join(docErrs...)
+}
+
+// processResource templates and rewrites a single document of a resource file.
+func processResource(
+	ctx context.Context,
+	tools Tools,
+	c *k8scfg.Client,
+	config string,
+	cf k8scfg.RawResource,
+	skipSecrets bool,
+) ([]k8scfg.Resource, error) {
+	res, err := c.TemplateConfigFile(config, cf.Config)
+	if err != nil {
+		if skipSecrets && errors.Is(err, ErrSecret) {
+			// just ignore this and keep going
+			log.Linef("SKIP", "Skip templating a resource in %q because it contains a secret.", config)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("c.TemplateConfigFile(): %w", err)
+	}
+
+	rewriteOpt := RewriteOptions{
+		SkipSecrets: skipSecrets,
+	}
+	routs, err := RewriteConfigFile(
+		ctx, tools, res, cf.ResourceOptions, Rewriters, &rewriteOpt)
+	if err != nil {
+		return nil, fmt.Errorf("c.RewriteConfigFile(): %w", err)
+	}
+
+	return routs, nil
 }
@@ -0,0 +1,105 @@
+package k8scfg_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/config"
+	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/manager/k8scfg"
+)
+
+// configMap returns a source document for a config map with the given name and
+// data value.
+func configMap(name, value string) string {
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  value: " + value + "\n"
+}
+
+func TestProcessResourceFile(t *testing.T) {
+	// skipped documents are logged
+	assert.NoError(t, log.Setup("", "", true, false))
+
+	t.Parallel()
+
+	failing := configMap("b", `{{{ fail "boom" }}}`)
+
+	tests := []struct {
+		name      string
+		source    string
+		keepGoing bool
+		expect    []string
+		errs      []string
+		errIs     error
+	}{
+		{
+			name:   "all succeed",
+			source: configMap("a", "1") + "---\n" + configMap("b", "2"),
+			expect: []string{"a", "b"},
+		},
+		{
+			name:   "failure stops",
+			source: configMap("a", "1") + "---\n" + failing + "---\n" + configMap("c", "3"),
+			errs:   []string{"boom"},
+		},
+		{
+			name:      "keep going keeps siblings",
+			source:    configMap("a", "1") + "---\n" + failing + "---\n" + configMap("c", "3"),
+			keepGoing: true,
+			expect:    []string{"a", "c"},
+			errs:      []string{"document 1", "boom"},
+		},
+		{
+			name:      "keep going reports each failure",
+			source:    failing + "---\n" + configMap("b", "2") + "---\n" + failing,
+			keepGoing: true,
+			expect:    []string{"b"},
+			errs:      []string{"document 0", "document 2"},
+		},
+		{
+			name:   "empty",
+			source: "",
+			errIs:  k8scfg.ErrNoResources,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			root := t.TempDir()
+			source := filepath.Join(root, "src", "app", "config.yaml")
+			assert.NoError(t, os.MkdirAll(filepath.Dir(source), 0755))
+			assert.NoError(t, os.WriteFile(source, []byte(tc.source), 0644))
+
+			cfg := &config.Config{CloudHome: root}
+			tools := cfg.Tools(&config.Cluster{SourceDir: "src"}, true)
+
+			resources, err := k8scfg.ProcessResourceFile(context.Background(), tools, source, true, tc.keepGoing)
+
+			names := []string{}
+			for _, r := range resources {
+				names = append(names, r.Data.GetName())
+			}
+			if tc.expect == nil {
+				assert.Empty(t, names)
+			} else {
+				assert.Equal(t, tc.expect, names)
+			}
+
+			switch {
+			case tc.errIs != nil:
+				assert.ErrorIs(t, err, tc.errIs)
+			case len(tc.errs) > 0:
+				for _, msg := range tc.errs {
+					assert.ErrorContains(t, err, msg)
+				}
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -8,26 +8,34 @@ import (
 	"github.com/zostay/genifest/pkg/client/k8s"
 )
 
+// ResourceFilePath returns the path of the resource file the serialized resource
+// is saved to within the given save directory.
+func ResourceFilePath(saveDir string, sr *k8s.SerializedResource) string {
+	return filepath.Join(saveDir, sr.ResourceID()) + ".yaml"
+}
+
 // SaveResourceFile turns a serialized resource into a resource file mounted in
-// the given save directory.
+// the given save directory. It returns true if the content of the resource file
+// was changed as a result. A resource file whose content would not change is
+// not rewritten.
 func SaveResourceFile(
 	ctx context.Context,
 	tools Tools,
 	saveDir string,
 	sr *k8s.SerializedResource,
 	skipSecrets bool,
-) error {
+) (bool, error) {
 	c, err := tools.ResMgr(ctx, skipSecrets)
 	if err != nil {
-		return fmt.Errorf("tools.ResMgr(): %w", err)
+		return false, fmt.Errorf("tools.ResMgr(): %w", err)
 	}
 
-	wfile := filepath.Join(saveDir, sr.ResourceID()) + ".yaml"
+	wfile := ResourceFilePath(saveDir, sr)
 
-	err = c.WriteResourceFile(wfile, sr.Bytes())
+	changed, err := c.UpdateResourceFile(wfile, sr.Bytes())
 	if err != nil {
-		return fmt.Errorf("c.WriteResourceFile(%q): %w", wfile, err)
+		return false, fmt.Errorf("c.UpdateResourceFile(%q): %w", wfile, err)
 	}
 
-	return nil
+	return changed, nil
 }
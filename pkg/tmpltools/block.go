@@ -0,0 +1,88 @@
+package tmpltools
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// BlockScalar formats the source as a YAML literal block scalar indented by the
+// given number of spaces. It is meant to be used directly after a mapping key,
+// as in:
+//
+//	data:
+//	  app.conf: {{{ file "app" "app.conf" | blockScalar 4 }}}
+//
+// The chomping indicator is chosen so the content survives a round-trip
+// exactly: "|-" when there is no trailing newline, "|" for a single trailing
+// newline, and "|+" when there are several. The output does not end with a
+// newline because the template supplies the one that ends the line.
+//
+// Content made up only of newlines has no lines for a block to hold, so it is
+// written as a double-quoted scalar instead.
+//
+// Some content cannot survive a round-trip through a literal block, so an
+// error is returned for it instead:
+//
+//   - YAML infers the indentation of the block from its first non-empty line,
+//     so that line may not start with a space. An indentation indicator would
+//     fix that, but it is counted from the indentation of the mapping key,
+//     which is not known here.
+//   - YAML reads every line break as a newline, so carriage returns are lost.
+//   - YAML does not permit control characters other than tab and newline.
+func BlockScalar(indent int, source string) (string, error) {
+	if source == "" {
+		return `""`, nil
+	}
+
+	if strings.Trim(source, "\n") == "" {
+		return strconv.Quote(source), nil
+	}
+
+	if strings.HasPrefix(strings.TrimLeft(source, "\n"), " ") {
+		return "", fmt.Errorf("blockScalar cannot embed content whose first non-empty line starts with a space")
+	}
+
+	if strings.Contains(source, "\r") {
+		return "", fmt.Errorf("blockScalar cannot embed content with carriage returns")
+	}
+
+	for _, r := range source {
+		if isBlockControl(r) {
+			return "", fmt.Errorf("blockScalar cannot embed content with the control character %U", r)
+		}
+	}
+
+	body := strings.TrimRight(source, "\n")
+	trailing := len(source) - len(body)
+
+	header := "|"
+	switch {
+	case trailing == 0:
+		header = "|-"
+	case trailing > 1:
+		header = "|+"
+	}
+
+	pad := strings.Repeat(" ", indent)
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = pad + line
+		}
+	}
+
+	res := header + "\n" + strings.Join(lines, "\n")
+	if trailing > 1 {
+		res += strings.Repeat("\n", trailing-1)
+	}
+
+	return res, nil
+}
+
+// isBlockControl returns true for the control characters that may not appear
+// in a YAML block scalar.
+func isBlockControl(r rune) bool {
+	return r != '\t' && r != '\n' && (unicode.IsControl(r) || r == '\uFEFF')
+}
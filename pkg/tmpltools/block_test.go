@@ -0,0 +1,61 @@
+package tmpltools_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+func TestBlockScalar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		indent int
+		source string
+		expect string
+	}{
+		{2, "", `""`},
+		{2, "\n", `"\n"`},
+		{2, "\n\n", `"\n\n"`},
+		{2, "a\nb", "|-\n  a\n  b"},
+		{2, "a\n\nb\n", "|\n  a\n\n  b"},
+		{4, "a\n\n", "|+\n    a\n"},
+		{2, "a\n  b\n", "|\n  a\n    b"},
+		{2, "\na\n", "|\n\n  a"},
+		{2, "a\tb  \n", "|\n  a\tb  "},
+	}
+
+	for _, tc := range tests {
+		out, err := tmpltools.BlockScalar(tc.indent, tc.source)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expect, out)
+
+		// the output must read back as exactly the source, both at the top
+		// level and nested beneath another key
+		var top map[string]string
+		err = yaml.Unmarshal([]byte("key: "+out+"\nnext: x\n"), &top)
+		assert.NoError(t, err, "%q", tc.source)
+		assert.Equal(t, tc.source, top["key"], "%q", tc.source)
+
+		nestedOut, err := tmpltools.BlockScalar(tc.indent+2, tc.source)
+		assert.NoError(t, err)
+
+		var nested map[string]map[string]string
+		err = yaml.Unmarshal([]byte("data:\n  key: "+nestedOut+"\n  next: x\n"), &nested)
+		assert.NoError(t, err, "%q", tc.source)
+		assert.Equal(t, tc.source, nested["data"]["key"], "%q", tc.source)
+	}
+
+	for _, source := range []string{
+		"  indented\nb\n",
+		"\n  indented\n",
+		"a\r\nb\r\n",
+		"a\x00b\n",
+	} {
+		_, err := tmpltools.BlockScalar(2, source)
+		assert.Error(t, err, "%q", source)
+	}
+}
@@ -0,0 +1,95 @@
+package tmpltools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zostay/genifest/pkg/log"
+)
+
+// DefaultCacheTTL is how long cached values are used when no TTL is set.
+const DefaultCacheTTL = time.Hour
+
+// Cache is a disk cache for values fetched from the network by template
+// functions. Secrets must never be stored here.
+type Cache struct {
+	// Dir is the directory cache entries are stored in.
+	Dir string
+
+	// TTL is how long an entry is used before it is fetched again. If zero,
+	// DefaultCacheTTL is used.
+	TTL time.Duration
+
+	// Disabled causes every value to be fetched and nothing to be stored.
+	Disabled bool
+}
+
+// cacheEntry is the on-disk format of a single cached value.
+type cacheEntry struct {
+	Key     string    `json:"key"`
+	Value   string    `json:"value"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// Get returns the cached value identified by the given key parts if it has not
+// expired. Otherwise, it calls fetch and caches the result. Expired values are
+// never returned, so an error from fetch is returned even when an expired
+// entry is present.
+func (c *Cache) Get(fetch func() (string, error), keyParts ...string) (string, error) {
+	if c == nil || c.Disabled {
+		return fetch()
+	}
+
+	key := strings.Join(keyParts, "\x00")
+	sum := sha256.Sum256([]byte(key))
+	path := filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+
+	ttl := c.TTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	var entry cacheEntry
+	if bs, err := os.ReadFile(path); err == nil {
+		cached := json.Unmarshal(bs, &entry) == nil && entry.Key == key
+		if cached && time.Since(entry.Fetched) < ttl {
+			return entry.Value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return "", err
+	}
+
+	entry = cacheEntry{Key: key, Value: value, Fetched: time.Now()}
+	if err := c.store(path, &entry); err != nil {
+		log.Linef("CACHE", "Unable to store cache entry for %q: %v", keyParts, err)
+	}
+
+	return value, nil
+}
+
+// store writes the entry to the given path.
+func (c *Cache) store(path string, entry *cacheEntry) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+
+	bs, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, bs, 0600); err != nil {
+		return fmt.Errorf("os.WriteFile(%q): %w", path, err)
+	}
+
+	return nil
+}
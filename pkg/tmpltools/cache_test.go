@@ -0,0 +1,83 @@
+package tmpltools_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+func TestCache_Get(t *testing.T) {
+	// cache problems are logged
+	assert.NoError(t, log.Setup("", "", true, false))
+
+	t.Parallel()
+
+	dir := t.TempDir()
+	fetches := 0
+	fetch := func(value string) func() (string, error) {
+		return func() (string, error) {
+			fetches++
+			return value, nil
+		}
+	}
+	failed := func() (string, error) {
+		fetches++
+		return "", errors.New("network is down")
+	}
+
+	c := &tmpltools.Cache{Dir: dir, TTL: time.Hour}
+
+	v, err := c.Get(fetch("first"), "known-host", "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+	assert.Equal(t, 1, fetches)
+
+	// a fresh entry is used without fetching
+	v, err = c.Get(fetch("second"), "known-host", "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+	assert.Equal(t, 1, fetches)
+
+	// other keys are fetched separately
+	v, err = c.Get(fetch("other"), "known-host", "gitlab.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", v)
+	assert.Equal(t, 2, fetches)
+
+	// an expired entry is fetched again
+	expired := &tmpltools.Cache{Dir: dir, TTL: time.Nanosecond}
+	time.Sleep(time.Millisecond)
+	v, err = expired.Get(fetch("third"), "known-host", "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "third", v)
+	assert.Equal(t, 3, fetches)
+
+	// an expired entry is never used, even when the fetch fails
+	time.Sleep(time.Millisecond)
+	_, err = expired.Get(failed, "known-host", "github.com")
+	assert.Error(t, err)
+	assert.Equal(t, 4, fetches)
+
+	// a fresh entry is used without fetching, even when offline
+	v, err = c.Get(failed, "known-host", "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "third", v)
+	assert.Equal(t, 4, fetches)
+
+	// without an entry, the failure is returned
+	_, err = c.Get(failed, "known-host", "example.com")
+	assert.Error(t, err)
+	assert.Equal(t, 5, fetches)
+
+	// nothing is cached when disabled
+	disabled := &tmpltools.Cache{Dir: dir, Disabled: true}
+	v, err = disabled.Get(fetch("fourth"), "known-host", "github.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "fourth", v)
+	assert.Equal(t, 6, fetches)
+}
@@ -0,0 +1,28 @@
+package tmpltools
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// MustEnvExpand replaces $VAR and ${VAR} references in the source with values
+// from the process environment, like the expandenv function of sprig, but
+// returns an error naming every variable referenced by the source that is not
+// set in the environment.
+func MustEnvExpand(source string) (string, error) {
+	var missing []string
+	res := os.Expand(source, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("undefined environment variables: %s", strings.Join(missing, ", "))
+	}
+
+	return res, nil
+}
@@ -1,14 +1,30 @@
 package tmpltools
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/zostay/genifest/pkg/log"
 )
 
+// AnyApp may be passed as the app to File to search for the file in the root of
+// the files directory and in every app directory.
+const AnyApp = "*"
+
 func File(cloudHome, app, path string) (string, error) {
 	p := filepath.Join(cloudHome, app, path)
+	if app == AnyApp {
+		var err error
+		p, err = findInAnyApp(cloudHome, path)
+		if err != nil {
+			return "", err
+		}
+	}
+
 	data, err := os.ReadFile(p)
 	log.LineBytes("EMBED", data)
 	if err != nil {
@@ -16,3 +32,107 @@ func File(cloudHome, app, path string) (string, error) {
 	}
 	return string(data), err
 }
+
+// Apps converts the app argument of the file template functions, which may be a
+// single app or a list of apps, into the list of apps to search.
+func Apps(app any) ([]string, error) {
+	switch app := app.(type) {
+	case string:
+		return []string{app}, nil
+	case []string:
+		return app, nil
+	case []any:
+		apps := make([]string, len(app))
+		for i, a := range app {
+			apps[i] = fmt.Sprint(a)
+		}
+		return apps, nil
+	default:
+		return nil, fmt.Errorf("app must be a string or a list of strings, not %T", app)
+	}
+}
+
+// FindFile returns the path to the file found in the first of the given app
+// directories that contains it. The apps are searched in the order given and
+// each may be AnyApp.
+func FindFile(cloudHome string, apps []string, path string) (string, error) {
+	for _, app := range apps {
+		if app == AnyApp {
+			// ambiguity is an error, but not finding it means keep looking
+			p, err := findInAnyApp(cloudHome, path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return p, err
+		}
+
+		p := filepath.Join(cloudHome, app, path)
+		fi, err := os.Stat(p)
+		if errors.Is(err, fs.ErrNotExist) || (err == nil && fi.IsDir()) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		return p, nil
+	}
+
+	return "", fmt.Errorf("file %q not found in any of the app directories %s of %q: %w",
+		path, strings.Join(apps, ", "), cloudHome, fs.ErrNotExist)
+}
+
+// FileFirst returns the content of the file found by FindFile.
+func FileFirst(cloudHome string, apps []string, path string) (string, error) {
+	p, err := FindFile(cloudHome, apps, path)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(p)
+	log.LineBytes("EMBED", data)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// findInAnyApp locates the given path either directly within the files root or
+// within one of the app directories immediately beneath it. It is an error if
+// the path is found in none of them or in more than one.
+func findInAnyApp(cloudHome, path string) (string, error) {
+	candidates := []string{filepath.Join(cloudHome, path)}
+	apps, err := os.ReadDir(cloudHome)
+	if err != nil {
+		return "", err
+	}
+
+	for _, app := range apps {
+		if app.IsDir() {
+			candidates = append(candidates, filepath.Join(cloudHome, app.Name(), path))
+		}
+	}
+
+	found := make([]string, 0, 1)
+	for _, c := range candidates {
+		fi, err := os.Stat(c)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+
+		if !fi.IsDir() {
+			found = append(found, c)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("file %q not found in any app directory of %q: %w", path, cloudHome, fs.ErrNotExist)
+	case 1:
+		return found[0], nil
+	default:
+		return "", fmt.Errorf("file %q is ambiguous, found: %s", path, strings.Join(found, ", "))
+	}
+}
@@ -0,0 +1,96 @@
+package tmpltools_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/log"
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+// writeFiles creates each of the named files beneath root, containing its own
+// name.
+func writeFiles(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+}
+
+func TestFileFirst(t *testing.T) {
+	// embedded files are logged
+	assert.NoError(t, log.Setup("", "", true, false))
+
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFiles(t, root,
+		"web/app.conf",
+		"base/app.conf",
+		"base/base.conf",
+		"shared.conf",
+		"api/only-api.conf",
+		"web/both.conf",
+		"api/both.conf",
+	)
+
+	tests := []struct {
+		name   string
+		apps   []string
+		path   string
+		expect string
+		err    error
+	}{
+		{"first app wins", []string{"web", "base"}, "app.conf", "web/app.conf", nil},
+		{"order matters", []string{"base", "web"}, "app.conf", "base/app.conf", nil},
+		{"falls back", []string{"web", "base"}, "base.conf", "base/base.conf", nil},
+		{"any app in root", []string{"web", "*"}, "shared.conf", "shared.conf", nil},
+		{"any app in app dir", []string{"web", "*"}, "only-api.conf", "api/only-api.conf", nil},
+		{"app before any app", []string{"web", "*"}, "both.conf", "web/both.conf", nil},
+		{"not found", []string{"web", "base"}, "missing.conf", "", fs.ErrNotExist},
+		{"not found anywhere", []string{"web", "*"}, "missing.conf", "", fs.ErrNotExist},
+	}
+
+	for _, tc := range tests {
+		out, err := tmpltools.FileFirst(root, tc.apps, tc.path)
+		if tc.err != nil {
+			assert.ErrorIs(t, err, tc.err, tc.name)
+			continue
+		}
+
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expect, out, tc.name)
+	}
+
+	// found in more than one app is ambiguous, which is not the same as missing
+	_, err := tmpltools.FileFirst(root, []string{"*"}, "both.conf")
+	assert.ErrorContains(t, err, "ambiguous")
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestFileFirst_Unreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("permissions are not enforced for root")
+	}
+
+	// embedded files are logged
+	assert.NoError(t, log.Setup("", "", true, false))
+
+	t.Parallel()
+
+	root := t.TempDir()
+	writeFiles(t, root, "web/app.conf", "base/app.conf")
+	assert.NoError(t, os.Chmod(filepath.Join(root, "web"), 0))
+	t.Cleanup(func() { _ = os.Chmod(filepath.Join(root, "web"), 0755) })
+
+	// an unreadable app directory is an error, not a reason to move on
+	_, err := tmpltools.FileFirst(root, []string{"web", "base"}, "app.conf")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+}
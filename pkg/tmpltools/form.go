@@ -0,0 +1,22 @@
+package tmpltools
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// FormDecode parses the source as an application/x-www-form-urlencoded string
+// and returns the value of the named key. The source comes last so that it can
+// be piped in from another template function.
+func FormDecode(key, source string) (string, error) {
+	values, err := url.ParseQuery(source)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse form encoded value: %w", err)
+	}
+
+	if !values.Has(key) {
+		return "", fmt.Errorf("form encoded value has no key named %q", key)
+	}
+
+	return values.Get(key), nil
+}
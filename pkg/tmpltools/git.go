@@ -0,0 +1,87 @@
+package tmpltools
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Git looks up information about the git repository containing Dir. Each value
+// is looked up only once.
+type Git struct {
+	// Context is used to run git commands.
+	Context context.Context
+
+	// Dir is the directory git commands are run from.
+	Dir string
+
+	values map[string]string
+}
+
+// gitFields maps each field of Ref to the git command that looks it up.
+var gitFields = map[string][]string{
+	"sha":       {"rev-parse", "HEAD"},
+	"short-sha": {"rev-parse", "--short", "HEAD"},
+	"branch":    {"rev-parse", "--abbrev-ref", "HEAD"},
+	"tag":       {"tag", "--points-at", "HEAD"},
+	"dirty":     {"status", "--porcelain"},
+}
+
+// run runs git with the given arguments and returns the trimmed output.
+func (g *Git) run(args ...string) (string, error) {
+	ctx := g.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Ref returns the value of the named field for the current commit:
+//
+//   - sha is the full commit hash
+//   - short-sha is the abbreviated commit hash
+//   - branch is the current branch name, or "HEAD" when detached
+//   - tag is the first tag pointing at the commit, or empty if there is none
+//   - dirty is "true" if there are uncommitted changes and "false" otherwise
+func (g *Git) Ref(field string) (string, error) {
+	if v, ok := g.values[field]; ok {
+		return v, nil
+	}
+
+	args, ok := gitFields[field]
+	if !ok {
+		return "", fmt.Errorf("unknown git field %q", field)
+	}
+
+	if _, err := g.run("rev-parse", "--git-dir"); err != nil {
+		return "", fmt.Errorf("%q is not in a git repository: %w", g.Dir, err)
+	}
+
+	out, err := g.run(args...)
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+
+	switch field {
+	case "tag":
+		out, _, _ = strings.Cut(out, "\n")
+	case "dirty":
+		out = fmt.Sprint(out != "")
+	}
+
+	if g.values == nil {
+		g.values = map[string]string{}
+	}
+	g.values[field] = out
+
+	return out, nil
+}
@@ -0,0 +1,28 @@
+package tmpltools
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RegexReplaceFirst replaces only the first match of the pattern in the source
+// with the replacement. The replacement may refer to capture groups using $1 or
+// ${name} syntax. This complements sprig's mustRegexReplaceAll, which replaces
+// every match.
+func RegexReplaceFirst(pattern, replacement, source string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	m := re.FindStringSubmatchIndex(source)
+	if m == nil {
+		return source, nil
+	}
+
+	res := []byte(source[:m[0]])
+	res = re.ExpandString(res, replacement, source, m)
+	res = append(res, source[m[1]:]...)
+
+	return string(res), nil
+}
@@ -88,9 +88,10 @@ func (g *Ghost) Secret(name string) (string, error) {
 	return s.Password(), nil
 }
 
-// KubeSeal runs the kubeseal command to output a raw sealed secret.
-func KubeSeal(ns, name, secret string) (string, error) {
-	cmd := exec.Command(
+// KubeSeal runs the kubeseal command to output a raw sealed secret. The command
+// is killed if the context is done first.
+func KubeSeal(ctx context.Context, ns, name, secret string) (string, error) {
+	cmd := exec.CommandContext(ctx,
 		"kubeseal", "--raw",
 		"--namespace", ns,
 		"--name", name,
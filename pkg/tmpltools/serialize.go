@@ -0,0 +1,47 @@
+package tmpltools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToYaml serializes the value as a YAML document indented by 2 spaces. This is
+// the reverse of reading a structured value with fileValue, as in:
+//
+//	data:
+//	  config.yaml: {{{ fileValue "app" "values.yaml" "config" | toYaml | blockScalar 4 }}}
+func ToYaml(v any) (string, error) {
+	return ToYamlIndent(2, v)
+}
+
+// ToYamlIndent serializes the value as a YAML document using the given number
+// of spaces for each level of indentation.
+func ToYamlIndent(indent int, v any) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(v); err != nil {
+		return "", fmt.Errorf("yaml.Encode(): %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("yaml.Close(): %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// ToJsonIndent serializes the value as JSON using the given number of spaces
+// for each level of indentation. Use toJson for compact JSON.
+func ToJsonIndent(indent int, v any) (string, error) {
+	bs, err := json.MarshalIndent(v, "", strings.Repeat(" ", indent))
+	if err != nil {
+		return "", fmt.Errorf("json.MarshalIndent(): %w", err)
+	}
+
+	return string(bs), nil
+}
@@ -0,0 +1,30 @@
+package tmpltools_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+func TestToYaml(t *testing.T) {
+	t.Parallel()
+
+	v := map[string]any{
+		"name":  "api",
+		"ports": []any{80, 443},
+	}
+
+	out, err := tmpltools.ToYaml(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "name: api\nports:\n  - 80\n  - 443\n", out)
+
+	out, err = tmpltools.ToYamlIndent(4, map[string]any{"a": map[string]any{"b": 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, "a:\n    b: 1\n", out)
+
+	out, err = tmpltools.ToJsonIndent(2, v)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"name\": \"api\",\n  \"ports\": [\n    80,\n    443\n  ]\n}", out)
+}
@@ -0,0 +1,44 @@
+package tmpltools
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// SourcePath returns part of the path to the source file being templated. The
+// paths returned are relative to root, which is normally the cloud home, and
+// use forward slashes, so output does not depend on where the configuration is
+// checked out. The part may be one of:
+//
+//   - "path" is the path to the source file
+//   - "dir" is the directory containing the source file
+//   - "basename" is the file name of the source file
+//   - "parentDir" is the directory containing dir
+//   - "app" is the name of the directory containing the source file, which is
+//     the same app name run uses to pick the deployment directory
+func SourcePath(root, name, part string) (string, error) {
+	if root != "" && filepath.IsAbs(name) {
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return "", fmt.Errorf("filepath.Rel(%q, %q): %w", root, name, err)
+		}
+		name = rel
+	}
+	name = filepath.ToSlash(name)
+
+	switch part {
+	case "path":
+		return name, nil
+	case "dir":
+		return path.Dir(name), nil
+	case "basename":
+		return path.Base(name), nil
+	case "parentDir":
+		return path.Dir(path.Dir(name)), nil
+	case "app":
+		return path.Base(path.Dir(name)), nil
+	default:
+		return "", fmt.Errorf("unknown source path part %q", part)
+	}
+}
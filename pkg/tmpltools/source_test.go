@@ -0,0 +1,38 @@
+package tmpltools_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+func TestSourcePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		part   string
+		expect string
+	}{
+		{"path", "src/web/deploy.yaml"},
+		{"dir", "src/web"},
+		{"basename", "deploy.yaml"},
+		{"parentDir", "src"},
+		{"app", "web"},
+	}
+
+	for _, tc := range tests {
+		out, err := tmpltools.SourcePath("/work/cloud", "/work/cloud/src/web/deploy.yaml", tc.part)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expect, out, tc.part)
+	}
+
+	// relative names are left relative
+	out, err := tmpltools.SourcePath("/work/cloud", "src/web/deploy.yaml", "dir")
+	assert.NoError(t, err)
+	assert.Equal(t, "src/web", out)
+
+	_, err = tmpltools.SourcePath("/work/cloud", "/work/cloud/src/web/deploy.yaml", "nope")
+	assert.Error(t, err)
+}
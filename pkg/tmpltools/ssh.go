@@ -1,6 +1,7 @@
 package tmpltools
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -22,9 +23,10 @@ func SSHKey(name string) (string, error) {
 	return strings.TrimSpace(string(bs)), nil
 }
 
-// SSHKnownHost looks up a known host entry for the given host.
-func SSHKnownHost(name string) (string, error) {
-	ksCmd := exec.Command("ssh-keyscan", name)
+// SSHKnownHost looks up a known host entry for the given host. The lookup is
+// killed if the context is done first.
+func SSHKnownHost(ctx context.Context, name string) (string, error) {
+	ksCmd := exec.CommandContext(ctx, "ssh-keyscan", name)
 	out, err := ksCmd.Output()
 	if err != nil {
 		return "", err
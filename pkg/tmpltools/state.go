@@ -0,0 +1,181 @@
+package tmpltools
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// stateLocks serializes access to each state file within the process.
+var stateLocks sync.Map
+
+const (
+	// stateLockWait is how long to wait for another process to release the
+	// lock on a state file.
+	stateLockWait = 30 * time.Second
+
+	// stateLockStale is the age at which a lock file is assumed to have been
+	// left behind by a process that died while holding it.
+	stateLockStale = 2 * time.Minute
+)
+
+// StateFile persists values between runs, so that a value generated once, such
+// as a random password, stays the same every time. The file is plain YAML, so
+// anything stored here is stored in the clear.
+type StateFile struct {
+	// Path is the location of the state file.
+	Path string
+
+	// ReadOnly prevents new values from being stored. Values already stored
+	// are still returned.
+	ReadOnly bool
+}
+
+// lock acquires the lock for the state file and returns the function that
+// releases it. The lock is held both within the process and, through a lock
+// file created next to the state file, between processes.
+func (s *StateFile) lock() (func(), error) {
+	mu, _ := stateLocks.LoadOrStore(filepath.Clean(s.Path), &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	lockPath := s.Path + ".lock"
+	dir := filepath.Dir(lockPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		mu.(*sync.Mutex).Unlock()
+		return nil, fmt.Errorf("os.MkdirAll(%q): %w", dir, err)
+	}
+
+	deadline := time.Now().Add(stateLockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_ = f.Close()
+			return func() {
+				_ = os.Remove(lockPath)
+				mu.(*sync.Mutex).Unlock()
+			}, nil
+		}
+
+		if !errors.Is(err, fs.ErrExist) {
+			mu.(*sync.Mutex).Unlock()
+			return nil, fmt.Errorf("os.OpenFile(%q): %w", lockPath, err)
+		}
+
+		if fi, err := os.Stat(lockPath); err == nil && time.Since(fi.ModTime()) > stateLockStale {
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			mu.(*sync.Mutex).Unlock()
+			return nil, fmt.Errorf("timed out waiting for the state file lock %q", lockPath)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// read loads the values stored in the state file. A missing file has no
+// values.
+func (s *StateFile) read() (map[string]string, error) {
+	values := map[string]string{}
+
+	bs, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return values, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("os.ReadFile(%q): %w", s.Path, err)
+	}
+
+	err = yaml.Unmarshal(bs, &values)
+	if err != nil {
+		return nil, fmt.Errorf("yaml.Unmarshal(%q): %w", s.Path, err)
+	}
+
+	return values, nil
+}
+
+// write replaces the state file with the given values. The new file is moved
+// into place so that a failed write never leaves a partial file behind.
+func (s *StateFile) write(values map[string]string) error {
+	bs, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("yaml.Marshal(): %w", err)
+	}
+
+	dir := filepath.Dir(s.Path)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("os.MkdirAll(%q): %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(s.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("os.CreateTemp(%q): %w", dir, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(bs)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write %q: %w", tmp.Name(), err)
+	}
+
+	err = os.Rename(tmp.Name(), s.Path)
+	if err != nil {
+		return fmt.Errorf("os.Rename(%q, %q): %w", tmp.Name(), s.Path, err)
+	}
+
+	return nil
+}
+
+// Value returns the value stored under key in the state file. If there is no
+// such value, the generated value is stored under key and returned instead.
+// Since template arguments are always evaluated, generated is computed on every
+// run, but only the first one is kept. When ReadOnly is set, the generated
+// value is returned without being stored.
+func (s *StateFile) Value(key string, generated any) (string, error) {
+	if s.ReadOnly {
+		values, err := s.read()
+		if err != nil {
+			return "", err
+		}
+
+		if v, ok := values[key]; ok {
+			return v, nil
+		}
+
+		return fmt.Sprint(generated), nil
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return "", err
+	}
+	defer unlock()
+
+	values, err := s.read()
+	if err != nil {
+		return "", err
+	}
+
+	if v, ok := values[key]; ok {
+		return v, nil
+	}
+
+	v := fmt.Sprint(generated)
+	values[key] = v
+	if err := s.write(values); err != nil {
+		return "", err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,91 @@
+package tmpltools_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+func TestStateFile_Value(t *testing.T) {
+	t.Parallel()
+
+	s := &tmpltools.StateFile{Path: filepath.Join(t.TempDir(), "state.yaml")}
+
+	v, err := s.Value("password", "first")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+
+	v, err = s.Value("password", "second")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+
+	// a fresh StateFile reads what the last one stored
+	s = &tmpltools.StateFile{Path: s.Path}
+	v, err = s.Value("password", "third")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c", "d"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			_, err := s.Value(key, key)
+			assert.NoError(t, err)
+		}(key)
+	}
+	wg.Wait()
+
+	for _, key := range []string{"a", "b", "c", "d", "password"} {
+		v, err := s.Value(key, "missing")
+		assert.NoError(t, err)
+		assert.NotEqual(t, "missing", v)
+	}
+}
+
+func TestStateFile_ReadOnly(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	s := &tmpltools.StateFile{Path: path}
+	_, err := s.Value("stored", "first")
+	assert.NoError(t, err)
+
+	ro := &tmpltools.StateFile{Path: path, ReadOnly: true}
+	v, err := ro.Value("stored", "second")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+
+	v, err = ro.Value("new", "generated")
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", v)
+
+	// the new value was not stored
+	v, err = s.Value("new", "later")
+	assert.NoError(t, err)
+	assert.Equal(t, "later", v)
+}
+
+func TestStateFile_StaleLock(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.yaml")
+	lockPath := path + ".lock"
+	assert.NoError(t, os.WriteFile(lockPath, nil, 0600))
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(lockPath, old, old))
+
+	s := &tmpltools.StateFile{Path: path}
+	v, err := s.Value("password", "first")
+	assert.NoError(t, err)
+	assert.Equal(t, "first", v)
+
+	// the lock is released after use
+	assert.NoFileExists(t, lockPath)
+}
@@ -0,0 +1,144 @@
+package tmpltools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValuesFiles reads values out of structured YAML or JSON files found in the
+// files directory. Each file is parsed only once, unless it is modified after
+// it was parsed.
+type ValuesFiles struct {
+	// Root is the files directory.
+	Root string
+
+	parsed map[string]parsedValues
+}
+
+// parsedValues is a parsed values file along with the modification time and
+// size of the file when it was parsed.
+type parsedValues struct {
+	modTime time.Time
+	size    int64
+	doc     any
+}
+
+// Value returns the value found at the given key in the named file. The key is
+// a dot-separated list of mapping keys and sequence indexes, such as
+// "database.hosts.0". Keys containing dots or other special characters must use
+// the quoted bracket form, as in `metadata.annotations["prometheus.io/scrape"]`.
+// Sequence indexes may also be written in brackets, as in "hosts[0]". An empty
+// key or "." returns the whole document. The app may be a list of apps or
+// AnyApp and the file is located the same way the file template function does.
+func (v *ValuesFiles) Value(app any, path, key string) (any, error) {
+	apps, err := Apps(app)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := FindFile(v.Root, apps, path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err = filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.parsed == nil {
+		v.parsed = map[string]parsedValues{}
+	}
+
+	parts, err := splitKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+
+	pv, ok := v.parsed[p]
+	if !ok || !pv.modTime.Equal(fi.ModTime()) || pv.size != fi.Size() {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		pv = parsedValues{modTime: fi.ModTime(), size: fi.Size()}
+		if err := yaml.Unmarshal(data, &pv.doc); err != nil {
+			return nil, fmt.Errorf("unable to parse %q: %w", p, err)
+		}
+
+		v.parsed[p] = pv
+	}
+
+	cur := pv.doc
+	for _, part := range parts {
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found in %q", key, p)
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("key %q has invalid index %q in %q", key, part, p)
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("key %q not found in %q", key, p)
+		}
+	}
+
+	return cur, nil
+}
+
+// splitKey breaks a key selector into its parts.
+func splitKey(key string) ([]string, error) {
+	parts := []string{}
+	rest := key
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if len(rest) > 1 && (rest[1] == '"' || rest[1] == '\'') {
+				quote := rest[1]
+				closing := strings.IndexByte(rest[2:], quote)
+				if closing < 0 || len(rest) < closing+4 || rest[closing+3] != ']' {
+					return nil, fmt.Errorf("key %q has an unterminated quoted key", key)
+				}
+				parts = append(parts, rest[2:closing+2])
+				rest = rest[closing+4:]
+				continue
+			}
+
+			if end < 0 {
+				return nil, fmt.Errorf("key %q has an unterminated bracket", key)
+			}
+			parts = append(parts, rest[1:end])
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			parts = append(parts, rest[:end])
+			rest = rest[end:]
+		}
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,117 @@
+package tmpltools_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zostay/genifest/pkg/tmpltools"
+)
+
+func TestValuesFiles_Value(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	err := os.MkdirAll(filepath.Join(root, "app"), 0755)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(root, "app", "values.yaml"), []byte(`
+metadata:
+  annotations:
+    prometheus.io/scrape: "true"
+hosts:
+  - one
+  - two
+`), 0644)
+	assert.NoError(t, err)
+
+	v := &tmpltools.ValuesFiles{Root: root}
+
+	val, err := v.Value("app", "values.yaml", `.metadata.annotations["prometheus.io/scrape"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", val)
+
+	val, err = v.Value("app", "values.yaml", `metadata.annotations['prometheus.io/scrape']`)
+	assert.NoError(t, err)
+	assert.Equal(t, "true", val)
+
+	val, err = v.Value("app", "values.yaml", "hosts.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "two", val)
+
+	val, err = v.Value("app", "values.yaml", "hosts[0]")
+	assert.NoError(t, err)
+	assert.Equal(t, "one", val)
+
+	_, err = v.Value("app", "values.yaml", "metadata.annotations.prometheus.io/scrape")
+	assert.Error(t, err)
+
+	_, err = v.Value("app", "values.yaml", `metadata["unterminated]`)
+	assert.Error(t, err)
+}
+
+func TestValuesFiles_ValueReparsesModified(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	err := os.MkdirAll(filepath.Join(root, "app"), 0755)
+	assert.NoError(t, err)
+
+	p := filepath.Join(root, "app", "values.yaml")
+	err = os.WriteFile(p, []byte("replicas: 1\n"), 0644)
+	assert.NoError(t, err)
+
+	v := &tmpltools.ValuesFiles{Root: root}
+
+	val, err := v.Value("app", "values.yaml", "replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	err = os.WriteFile(p, []byte("replicas: 3\n"), 0644)
+	assert.NoError(t, err)
+
+	later := time.Now().Add(time.Hour)
+	err = os.Chtimes(p, later, later)
+	assert.NoError(t, err)
+
+	val, err = v.Value("app", "values.yaml", "replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, val)
+}
+
+func TestValuesFiles_ValueApps(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, f := range []struct{ app, content string }{
+		{"base", "replicas: 1\nport: 80\n"},
+		{"web", "replicas: 3\n"},
+	} {
+		err := os.MkdirAll(filepath.Join(root, f.app), 0755)
+		assert.NoError(t, err)
+		err = os.WriteFile(filepath.Join(root, f.app, "values.yaml"), []byte(f.content), 0644)
+		assert.NoError(t, err)
+	}
+	err := os.WriteFile(filepath.Join(root, "base", "only.yaml"), []byte("port: 8080\n"), 0644)
+	assert.NoError(t, err)
+
+	v := &tmpltools.ValuesFiles{Root: root}
+
+	val, err := v.Value([]any{"web", "base"}, "values.yaml", "replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, val)
+
+	val, err = v.Value([]string{"base", "web"}, "values.yaml", "replicas")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, val)
+
+	val, err = v.Value(tmpltools.AnyApp, "only.yaml", "port")
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, val)
+
+	_, err = v.Value(tmpltools.AnyApp, "values.yaml", "port")
+	assert.ErrorContains(t, err, "ambiguous")
+}